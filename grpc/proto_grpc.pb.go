@@ -23,6 +23,39 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MutualExlusionServiceClient interface {
 	AskPermission(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Ricart–Agrawala: the answer to a request deferred before a crash, kept as a permission
+	GivePermission(ctx context.Context, in *Permission, opts ...grpc.CallOption) (*Answer, error)
+	// Lamport algorithm: the request is queued by every peer and answered immediately
+	LamportRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Lamport algorithm: the time of the question is the time of the released request
+	LamportRelease(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Suzuki–Kasami: the request is sent to every peer, the answer is true if the token is sent back
+	SuzukiKasamiRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Suzuki–Kasami: gives the token to the receiving peer
+	SuzukiKasamiToken(ctx context.Context, in *Token, opts ...grpc.CallOption) (*Answer, error)
+	// Maekawa: all the messages between a peer and the members of its voting set
+	Maekawa(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*Answer, error)
+	// Raymond: asks the neighbour in the direction of the token to send it
+	RaymondRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Raymond: gives the token to a neighbour
+	RaymondPrivilege(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Centralized: asks the coordinator for the lock, answered with reply true when granted
+	CentralRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Centralized: gives the lock back to the coordinator
+	CentralRelease(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// Centralized: election message of the Bully or Ring algorithm, with Bully the answer is the OK message
+	Elect(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Answer, error)
+	// Centralized: announces the new coordinator, the answer tells if the
+	// peer holds the lock (reply) or waits for it (request_time)
+	Coordinator(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Answer, error)
+	// failure detector: sent periodically to every peer, it doesn't tick the Lamport clock
+	Heartbeat(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error)
+	// membership: a new peer asks a member to add it, the member takes the lock and tells the others
+	AddMember(ctx context.Context, in *Membership, opts ...grpc.CallOption) (*Members, error)
+	// membership: a peer leaving the network tells the others
+	RemoveMember(ctx context.Context, in *Membership, opts ...grpc.CallOption) (*Answer, error)
+	// discovery: exchanges the membership state with a random member, it doesn't tick the Lamport clock
+	Gossip(ctx context.Context, in *Rumors, opts ...grpc.CallOption) (*Rumors, error)
 }
 
 type mutualExlusionServiceClient struct {
@@ -42,11 +75,188 @@ func (c *mutualExlusionServiceClient) AskPermission(ctx context.Context, in *Que
 	return out, nil
 }
 
+func (c *mutualExlusionServiceClient) GivePermission(ctx context.Context, in *Permission, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/GivePermission", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) LamportRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/LamportRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) LamportRelease(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/LamportRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) SuzukiKasamiRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/SuzukiKasamiRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) SuzukiKasamiToken(ctx context.Context, in *Token, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/SuzukiKasamiToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) Maekawa(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/Maekawa", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) RaymondRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/RaymondRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) RaymondPrivilege(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/RaymondPrivilege", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) CentralRequest(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/CentralRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) CentralRelease(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/CentralRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) Elect(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/Elect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) Coordinator(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/Coordinator", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) Heartbeat(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) AddMember(ctx context.Context, in *Membership, opts ...grpc.CallOption) (*Members, error) {
+	out := new(Members)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/AddMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) RemoveMember(ctx context.Context, in *Membership, opts ...grpc.CallOption) (*Answer, error) {
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/RemoveMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExlusionServiceClient) Gossip(ctx context.Context, in *Rumors, opts ...grpc.CallOption) (*Rumors, error) {
+	out := new(Rumors)
+	err := c.cc.Invoke(ctx, "/proto.MutualExlusionService/Gossip", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MutualExlusionServiceServer is the server API for MutualExlusionService service.
 // All implementations must embed UnimplementedMutualExlusionServiceServer
 // for forward compatibility
 type MutualExlusionServiceServer interface {
 	AskPermission(context.Context, *Question) (*Answer, error)
+	// Ricart–Agrawala: the answer to a request deferred before a crash, kept as a permission
+	GivePermission(context.Context, *Permission) (*Answer, error)
+	// Lamport algorithm: the request is queued by every peer and answered immediately
+	LamportRequest(context.Context, *Question) (*Answer, error)
+	// Lamport algorithm: the time of the question is the time of the released request
+	LamportRelease(context.Context, *Question) (*Answer, error)
+	// Suzuki–Kasami: the request is sent to every peer, the answer is true if the token is sent back
+	SuzukiKasamiRequest(context.Context, *Question) (*Answer, error)
+	// Suzuki–Kasami: gives the token to the receiving peer
+	SuzukiKasamiToken(context.Context, *Token) (*Answer, error)
+	// Maekawa: all the messages between a peer and the members of its voting set
+	Maekawa(context.Context, *Vote) (*Answer, error)
+	// Raymond: asks the neighbour in the direction of the token to send it
+	RaymondRequest(context.Context, *Question) (*Answer, error)
+	// Raymond: gives the token to a neighbour
+	RaymondPrivilege(context.Context, *Question) (*Answer, error)
+	// Centralized: asks the coordinator for the lock, answered with reply true when granted
+	CentralRequest(context.Context, *Question) (*Answer, error)
+	// Centralized: gives the lock back to the coordinator
+	CentralRelease(context.Context, *Question) (*Answer, error)
+	// Centralized: election message of the Bully or Ring algorithm, with Bully the answer is the OK message
+	Elect(context.Context, *Election) (*Answer, error)
+	// Centralized: announces the new coordinator, the answer tells if the
+	// peer holds the lock (reply) or waits for it (request_time)
+	Coordinator(context.Context, *Election) (*Answer, error)
+	// failure detector: sent periodically to every peer, it doesn't tick the Lamport clock
+	Heartbeat(context.Context, *Question) (*Answer, error)
+	// membership: a new peer asks a member to add it, the member takes the lock and tells the others
+	AddMember(context.Context, *Membership) (*Members, error)
+	// membership: a peer leaving the network tells the others
+	RemoveMember(context.Context, *Membership) (*Answer, error)
+	// discovery: exchanges the membership state with a random member, it doesn't tick the Lamport clock
+	Gossip(context.Context, *Rumors) (*Rumors, error)
 	mustEmbedUnimplementedMutualExlusionServiceServer()
 }
 
@@ -57,6 +267,54 @@ type UnimplementedMutualExlusionServiceServer struct {
 func (UnimplementedMutualExlusionServiceServer) AskPermission(context.Context, *Question) (*Answer, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AskPermission not implemented")
 }
+func (UnimplementedMutualExlusionServiceServer) GivePermission(context.Context, *Permission) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GivePermission not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) LamportRequest(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LamportRequest not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) LamportRelease(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LamportRelease not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) SuzukiKasamiRequest(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuzukiKasamiRequest not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) SuzukiKasamiToken(context.Context, *Token) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuzukiKasamiToken not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) Maekawa(context.Context, *Vote) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Maekawa not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) RaymondRequest(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RaymondRequest not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) RaymondPrivilege(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RaymondPrivilege not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) CentralRequest(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CentralRequest not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) CentralRelease(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CentralRelease not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) Elect(context.Context, *Election) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Elect not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) Coordinator(context.Context, *Election) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Coordinator not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) Heartbeat(context.Context, *Question) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) AddMember(context.Context, *Membership) (*Members, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMember not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) RemoveMember(context.Context, *Membership) (*Answer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedMutualExlusionServiceServer) Gossip(context.Context, *Rumors) (*Rumors, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Gossip not implemented")
+}
 func (UnimplementedMutualExlusionServiceServer) mustEmbedUnimplementedMutualExlusionServiceServer() {}
 
 // UnsafeMutualExlusionServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +346,294 @@ func _MutualExlusionService_AskPermission_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MutualExlusionService_GivePermission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Permission)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).GivePermission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/GivePermission",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).GivePermission(ctx, req.(*Permission))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_LamportRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).LamportRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/LamportRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).LamportRequest(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_LamportRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).LamportRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/LamportRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).LamportRelease(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_SuzukiKasamiRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).SuzukiKasamiRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/SuzukiKasamiRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).SuzukiKasamiRequest(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_SuzukiKasamiToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Token)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).SuzukiKasamiToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/SuzukiKasamiToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).SuzukiKasamiToken(ctx, req.(*Token))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_Maekawa_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vote)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).Maekawa(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/Maekawa",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).Maekawa(ctx, req.(*Vote))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_RaymondRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).RaymondRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/RaymondRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).RaymondRequest(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_RaymondPrivilege_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).RaymondPrivilege(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/RaymondPrivilege",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).RaymondPrivilege(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_CentralRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).CentralRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/CentralRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).CentralRequest(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_CentralRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).CentralRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/CentralRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).CentralRelease(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_Elect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Election)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).Elect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/Elect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).Elect(ctx, req.(*Election))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_Coordinator_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Election)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).Coordinator(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/Coordinator",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).Coordinator(ctx, req.(*Election))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).Heartbeat(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_AddMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Membership)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).AddMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/AddMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).AddMember(ctx, req.(*Membership))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Membership)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/RemoveMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).RemoveMember(ctx, req.(*Membership))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExlusionService_Gossip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Rumors)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExlusionServiceServer).Gossip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.MutualExlusionService/Gossip",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExlusionServiceServer).Gossip(ctx, req.(*Rumors))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MutualExlusionService_ServiceDesc is the grpc.ServiceDesc for MutualExlusionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,6 +645,70 @@ var MutualExlusionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AskPermission",
 			Handler:    _MutualExlusionService_AskPermission_Handler,
 		},
+		{
+			MethodName: "GivePermission",
+			Handler:    _MutualExlusionService_GivePermission_Handler,
+		},
+		{
+			MethodName: "LamportRequest",
+			Handler:    _MutualExlusionService_LamportRequest_Handler,
+		},
+		{
+			MethodName: "LamportRelease",
+			Handler:    _MutualExlusionService_LamportRelease_Handler,
+		},
+		{
+			MethodName: "SuzukiKasamiRequest",
+			Handler:    _MutualExlusionService_SuzukiKasamiRequest_Handler,
+		},
+		{
+			MethodName: "SuzukiKasamiToken",
+			Handler:    _MutualExlusionService_SuzukiKasamiToken_Handler,
+		},
+		{
+			MethodName: "Maekawa",
+			Handler:    _MutualExlusionService_Maekawa_Handler,
+		},
+		{
+			MethodName: "RaymondRequest",
+			Handler:    _MutualExlusionService_RaymondRequest_Handler,
+		},
+		{
+			MethodName: "RaymondPrivilege",
+			Handler:    _MutualExlusionService_RaymondPrivilege_Handler,
+		},
+		{
+			MethodName: "CentralRequest",
+			Handler:    _MutualExlusionService_CentralRequest_Handler,
+		},
+		{
+			MethodName: "CentralRelease",
+			Handler:    _MutualExlusionService_CentralRelease_Handler,
+		},
+		{
+			MethodName: "Elect",
+			Handler:    _MutualExlusionService_Elect_Handler,
+		},
+		{
+			MethodName: "Coordinator",
+			Handler:    _MutualExlusionService_Coordinator_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _MutualExlusionService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "AddMember",
+			Handler:    _MutualExlusionService_AddMember_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _MutualExlusionService_RemoveMember_Handler,
+		},
+		{
+			MethodName: "Gossip",
+			Handler:    _MutualExlusionService_Gossip_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc/proto.proto",
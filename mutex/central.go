@@ -0,0 +1,439 @@
+package mutex
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// errNotCoordinator is returned to the requests received by a peer that is no more, or not yet, the coordinator
+var errNotCoordinator = errors.New("mutex: not the coordinator")
+
+// centralRequest is a request queued at the coordinator
+type centralRequest struct {
+	id   string
+	time int
+	// closed when the lock is granted
+	grant chan struct{}
+}
+
+// CentralMutex is a distributed mutex managed by a coordinator: every peer
+// sends REQUEST to the coordinator and waits for the GRANT, then sends RELEASE
+// when it leaves the critical section. The coordinator serves the requests in
+// FIFO order, it can also use the lock itself.
+// It needs 3 messages per entry.
+//
+// When the coordinator stops answering a new one is elected, with the Bully
+// or the Ring algorithm, and announced with a new epoch. It takes over the
+// lock asking every peer if it holds the lock or waits for it.
+type CentralMutex struct {
+	*node
+	election string
+	// mu protects all the fields below
+	mu          sync.Mutex
+	coordinator string
+	epoch       int
+	// closed and replaced when a coordinator is announced
+	announced chan struct{}
+	// an election started by this peer is running
+	electing bool
+
+	// coordinator side
+	// request holding the lock, nil if it is free
+	holder *centralRequest
+	queue  []*centralRequest
+	// closed when this peer stops being the coordinator
+	deposed chan struct{}
+
+	// requester side
+	// time of the request of this peer
+	mine    int
+	waiting bool
+	holding bool
+}
+
+// NewCentral creates a CentralMutex, call Listen and Connect before using it.
+// config.Coordinator is the lock server, empty means this peer.
+func NewCentral(config Config) *CentralMutex {
+	m := &CentralMutex{
+		node:      newNode(config),
+		election:  config.Election,
+		announced: make(chan struct{}),
+		deposed:   make(chan struct{}),
+	}
+	if m.election == "" {
+		m.election = Bully
+	}
+	m.coordinator = config.Coordinator
+	if m.coordinator == "" {
+		m.coordinator = m.self()
+	}
+	return m
+}
+
+// Listen opens the port to new connections and serves the gRPC service in background.
+// It returns once the port is open, so it is safe to connect to the others peers after it.
+func (m *CentralMutex) Listen() error {
+	return m.listen(m)
+}
+
+// Lock blocks until the coordinator grants the lock.
+// If ctx is done or the timeout expires before, the request is removed from
+// the queue of the coordinator and ctx.Err() or ErrTimeout is returned.
+func (m *CentralMutex) Lock(ctx context.Context) error {
+	_, err := m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, false) })
+	return err
+}
+
+// TryLock is like Lock but returns false, without waiting, when the lock is
+// held or requested by another peer.
+func (m *CentralMutex) TryLock(ctx context.Context) (bool, error) {
+	return m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, true) })
+}
+
+// Unlock gives the lock back to the coordinator, if it is not available the
+// lock is given back to the new one once it is elected
+func (m *CentralMutex) Unlock() {
+	m.mustHold("")
+	defer m.exit("")
+	log.Printf("Lamport %d: Ending critical section", m.clock.Tick())
+	m.mu.Lock()
+	m.holding = false
+	mine := m.mine
+	m.mu.Unlock()
+
+	ctx, cancel := m.withTimeout(context.Background())
+	defer cancel()
+	for {
+		coordinator, err := m.giveBack(ctx, mine)
+		if err == nil {
+			return
+		}
+		log.Printf("Lamport %d: Could not release the lock, coordinator [%s] not available: %v", m.clock.Now(), coordinator, err)
+		if m.elect(ctx, coordinator) != nil {
+			return
+		}
+	}
+}
+
+func (m *CentralMutex) acquire(ctx context.Context, try bool) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	m.mine = m.clock.Tick()
+	m.waiting = true
+	mine := m.mine
+	m.mu.Unlock()
+
+	for {
+		coordinator, granted, err := m.ask(ctx, mine, try)
+		if err == nil {
+			m.mu.Lock()
+			m.waiting = false
+			m.holding = granted
+			m.mu.Unlock()
+			if granted {
+				log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+			} else {
+				log.Printf("Lamport %d: The coordinator denied the lock", m.clock.Now())
+			}
+			return granted, nil
+		}
+		if refused(err) {
+			// the coordinator is there, electing another one would make two
+			log.Printf("Lamport %d: Coordinator [%s] refused the request: %v", m.clock.Now(), coordinator, err)
+			m.mu.Lock()
+			m.waiting = false
+			m.mu.Unlock()
+			return false, refusal(coordinator, err)
+		}
+		if ctx.Err() == nil {
+			log.Printf("Lamport %d: Coordinator [%s] not available: %v", m.clock.Now(), coordinator, err)
+			m.elect(ctx, coordinator)
+		}
+		if ctx.Err() != nil {
+			log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+			m.mu.Lock()
+			m.waiting = false
+			m.mu.Unlock()
+			// a new coordinator could have queued the request when taking over
+			releaseCtx, cancelRelease := m.withTimeout(context.Background())
+			defer cancelRelease()
+			m.giveBack(releaseCtx, mine)
+			return false, abandoned(ctx)
+		}
+	}
+}
+
+// ask sends the request made at time mine to the current coordinator, which is returned
+func (m *CentralMutex) ask(ctx context.Context, mine int, try bool) (string, bool, error) {
+	coordinator, epoch := m.current()
+	if coordinator == m.self() || coordinator == "" {
+		// empty while this peer takes over
+		granted, err := m.request(ctx, m.id, mine, try)
+		return coordinator, granted, err
+	}
+	peer, err := m.client(coordinator)
+	if err != nil {
+		return coordinator, false, err
+	}
+	log.Printf("Lamport %d: Asked coordinator [%s] for the lock", m.clock.Tick(), coordinator)
+	answer, err := peer.CentralRequest(ctx, m.question(mine, epoch, try))
+	if err != nil {
+		return coordinator, false, err
+	}
+	m.clock.Witness(int(answer.Time))
+	return coordinator, answer.Reply, nil
+}
+
+// giveBack releases the request made at time mine, granted or not, at the current coordinator, which is returned
+func (m *CentralMutex) giveBack(ctx context.Context, mine int) (string, error) {
+	coordinator, epoch := m.current()
+	if coordinator == m.self() {
+		m.release(m.id, mine)
+		return coordinator, nil
+	}
+	peer, err := m.client(coordinator)
+	if err != nil {
+		return coordinator, err
+	}
+	answer, err := peer.CentralRelease(ctx, m.question(mine, epoch, false))
+	if err != nil {
+		return coordinator, err
+	}
+	m.clock.Witness(int(answer.Time))
+	return coordinator, nil
+}
+
+// current returns the coordinator and its epoch
+func (m *CentralMutex) current() (string, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.coordinator, m.epoch
+}
+
+func (m *CentralMutex) question(time int, epoch int, try bool) *proto.Question {
+	return &proto.Question{
+		ClientReference: m.reference(),
+		Time:            int32(time),
+		PeerId:          m.id,
+		TryLock:         try,
+		Epoch:           int32(epoch),
+	}
+}
+
+// request waits in the queue of the coordinator until the lock is granted.
+// If ctx is done before, the request is removed from the queue.
+// A request already taken over from the previous coordinator keeps its place.
+func (m *CentralMutex) request(ctx context.Context, id string, time int, try bool) (bool, error) {
+	m.mu.Lock()
+	for m.coordinator == "" {
+		// wait for the takeover of this peer
+		announced := m.announced
+		m.mu.Unlock()
+		select {
+		case <-announced:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		m.mu.Lock()
+	}
+	if m.coordinator != m.self() {
+		m.mu.Unlock()
+		return false, errNotCoordinator
+	}
+	deposed := m.deposed
+	if m.holder != nil && m.holder.id == id && m.holder.time == time {
+		m.mu.Unlock()
+		return true, nil
+	}
+	request := m.find(id, time)
+	if request == nil {
+		request = &centralRequest{id: id, time: time, grant: make(chan struct{})}
+		if m.holder == nil && len(m.queue) == 0 {
+			m.holder = request
+			m.mu.Unlock()
+			return true, nil
+		}
+		if try {
+			m.mu.Unlock()
+			return false, nil
+		}
+		m.queue = append(m.queue, request)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-request.grant:
+		return true, nil
+	case <-deposed:
+		return false, errNotCoordinator
+	case <-ctx.Done():
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.holder == request {
+			// granted at the same time, nobody will release it
+			m.grantNext()
+		} else {
+			m.dequeue(request)
+		}
+		return false, ctx.Err()
+	}
+}
+
+// release frees the lock if it is held by the request of peer id made at
+// time, or removes the request from the queue if it is still waiting
+func (m *CentralMutex) release(id string, time int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.holder != nil && m.holder.id == id && m.holder.time == time {
+		m.grantNext()
+		return
+	}
+	if request := m.find(id, time); request != nil {
+		m.dequeue(request)
+	}
+}
+
+// checkEpoch refuses a request or a release for another epoch than the one of
+// this coordinator, the requester elects the coordinator again. With an older
+// epoch it missed the announcement of this one. With a newer epoch this peer
+// missed the takeover of another coordinator, or restarted, so it doesn't know
+// who holds the lock and steps down. During the takeover of this peer the
+// older requests wait for it, they are taken over.
+func (m *CentralMutex) checkEpoch(epoch int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if epoch < m.epoch && m.coordinator != "" {
+		return status.Errorf(codes.FailedPrecondition, "mutex: epoch %d is older than %d", epoch, m.epoch)
+	}
+	if epoch > m.epoch {
+		log.Printf("Lamport %d: Epoch %d is newer than %d, not the coordinator anymore", m.clock.Now(), epoch, m.epoch)
+		if m.coordinator == m.self() {
+			m.stepDown()
+			// the coordinator is not known until the next announcement
+			m.coordinator = ""
+		}
+		m.epoch = epoch
+		return status.Errorf(codes.FailedPrecondition, "mutex: epoch %d is newer than the one of this peer", epoch)
+	}
+	return nil
+}
+
+// stepDown stops serving the requests as coordinator, m.mu must be held
+func (m *CentralMutex) stepDown() {
+	close(m.deposed)
+	m.deposed = make(chan struct{})
+	m.holder = nil
+	m.queue = nil
+}
+
+// coordinatorOf returns the coordinator known by this peer and its epoch,
+// told to the peers joining through it
+func (m *CentralMutex) coordinatorOf() (string, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.coordinator, m.epoch
+}
+
+// adopt takes the coordinator of the members this peer joined, the one of
+// its configuration can be only the member it contacted first
+func (m *CentralMutex) adopt(coordinator string, epoch int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if coordinator == "" || epoch < m.epoch || coordinator == m.coordinator {
+		return
+	}
+	if m.coordinator == m.self() {
+		m.stepDown()
+	}
+	m.coordinator = coordinator
+	m.epoch = epoch
+	m.announce()
+	log.Printf("Lamport %d: Coordinator [%s] of epoch %d taken from the members", m.clock.Now(), coordinator, epoch)
+}
+
+// find returns the queued request of peer id made at time, m.mu must be held
+func (m *CentralMutex) find(id string, time int) *centralRequest {
+	for _, queued := range m.queue {
+		if queued.id == id && queued.time == time {
+			return queued
+		}
+	}
+	return nil
+}
+
+// grantNext gives the lock to the first request of the queue, m.mu must be held
+func (m *CentralMutex) grantNext() {
+	m.holder = nil
+	if len(m.queue) == 0 {
+		return
+	}
+	m.holder = m.queue[0]
+	m.queue = m.queue[1:]
+	close(m.holder.grant)
+}
+
+// dequeue removes request from the queue, m.mu must be held
+func (m *CentralMutex) dequeue(request *centralRequest) {
+	for i, queued := range m.queue {
+		if queued == request {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *CentralMutex) CentralRequest(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Peer [%s] asked for the lock", m.clock.Now(), peerRef)
+
+	if err := m.checkEpoch(int(in.Epoch)); err != nil {
+		log.Printf("Lamport %d: Request of peer [%s] not served: %v", m.clock.Now(), peerRef, err)
+		return nil, err
+	}
+	granted, err := m.request(ctx, in.PeerId, int(in.Time), in.TryLock)
+	if err != nil {
+		log.Printf("Lamport %d: Request of peer [%s] not served: %v", m.clock.Now(), peerRef, err)
+		return nil, err
+	}
+	if granted {
+		log.Printf("Lamport %d: Lock granted to peer [%s]", m.clock.Now(), peerRef)
+	} else {
+		log.Printf("Lamport %d: Lock denied to peer [%s]", m.clock.Now(), peerRef)
+	}
+	return &proto.Answer{
+		Reply: granted,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
+
+func (m *CentralMutex) CentralRelease(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Peer [%s] released the lock", m.clock.Now(), peerRef)
+
+	if err := m.checkEpoch(int(in.Epoch)); err != nil {
+		log.Printf("Lamport %d: Release of peer [%s] not applied: %v", m.clock.Now(), peerRef, err)
+		return nil, err
+	}
+	m.mu.Lock()
+	coordinator := m.coordinator
+	m.mu.Unlock()
+	if coordinator != m.self() {
+		return nil, errNotCoordinator
+	}
+	m.release(in.PeerId, int(in.Time))
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
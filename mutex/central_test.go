@@ -0,0 +1,44 @@
+package mutex
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+func TestCentralEpoch(t *testing.T) {
+	ports := freePorts(t, 2)
+	m := NewCentral(Config{Address: "127.0.0.1", Port: ports[0]})
+	defer m.Close()
+	m.epoch = 2
+	question := func(epoch int) *proto.Question {
+		return &proto.Question{
+			ClientReference: &proto.ClientReference{ClientAddress: "127.0.0.1", ClientPort: int32(ports[1])},
+			Time:            1,
+			PeerId:          "other",
+			Epoch:           int32(epoch),
+		}
+	}
+
+	if _, err := m.CentralRequest(context.Background(), question(1)); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("request of an older epoch: got %v, want FailedPrecondition", err)
+	}
+	if _, err := m.CentralRelease(context.Background(), question(1)); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("release of an older epoch: got %v, want FailedPrecondition", err)
+	}
+	if m.holder != nil {
+		t.Fatal("lock granted to a request of an older epoch")
+	}
+
+	// a newer epoch: another coordinator took over, this one must not grant anymore
+	if _, err := m.CentralRequest(context.Background(), question(3)); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("request of a newer epoch: got %v, want FailedPrecondition", err)
+	}
+	if m.holder != nil || m.coordinator == m.self() || m.epoch != 3 {
+		t.Errorf("after a request of a newer epoch: holder %v, coordinator %q, epoch %d", m.holder, m.coordinator, m.epoch)
+	}
+}
@@ -0,0 +1,35 @@
+package mutex
+
+import "sync/atomic"
+
+// LamportClock is a logical clock incremented when a message is received or
+// sent. It is safe to use from several goroutines and its zero value is a
+// clock at time 0.
+type LamportClock struct {
+	time int64
+}
+
+// Tick increments the clock for a local event or a sent message and returns the new time
+func (c *LamportClock) Tick() int {
+	return int(atomic.AddInt64(&c.time, 1))
+}
+
+// Witness moves the clock after the time carried by a received message and returns the new time
+func (c *LamportClock) Witness(remote int) int {
+	for {
+		now := atomic.LoadInt64(&c.time)
+		next := now
+		if int64(remote) > next {
+			next = int64(remote)
+		}
+		next++
+		if atomic.CompareAndSwapInt64(&c.time, now, next) {
+			return int(next)
+		}
+	}
+}
+
+// Now returns the current time without changing it
+func (c *LamportClock) Now() int {
+	return int(atomic.LoadInt64(&c.time))
+}
@@ -0,0 +1,84 @@
+package mutex
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestClockTickConcurrent(t *testing.T) {
+	const goroutines, ticks = 16, 1000
+	var clock LamportClock
+	var mu sync.Mutex
+	seen := make(map[int]bool, goroutines*ticks)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			times := make([]int, 0, ticks)
+			last := 0
+			for i := 0; i < ticks; i++ {
+				now := clock.Tick()
+				if now <= last {
+					t.Errorf("Tick went from %d to %d", last, now)
+				}
+				last = now
+				times = append(times, now)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, now := range times {
+				if seen[now] {
+					t.Errorf("Tick returned %d twice", now)
+				}
+				seen[now] = true
+			}
+		}()
+	}
+	wg.Wait()
+	if now := clock.Now(); now != goroutines*ticks {
+		t.Errorf("Now() = %d after %d ticks", now, goroutines*ticks)
+	}
+}
+
+func TestClockWitnessConcurrent(t *testing.T) {
+	const goroutines, events = 16, 1000
+	var clock LamportClock
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			last := 0
+			for i := 0; i < events; i++ {
+				var now int
+				// remote times ahead and behind the clock
+				remote := (i*goroutines + g) * 3 % (goroutines * events)
+				if i%2 == 0 {
+					now = clock.Witness(remote)
+					if now <= remote {
+						t.Errorf("Witness(%d) = %d, not after the remote time", remote, now)
+					}
+				} else {
+					now = clock.Tick()
+				}
+				if now <= last {
+					t.Errorf("clock went from %d to %d", last, now)
+				}
+				last = now
+			}
+		}(g)
+	}
+	wg.Wait()
+	if now := clock.Now(); now < goroutines*events {
+		t.Errorf("Now() = %d after %d events", now, goroutines*events)
+	}
+}
+
+func TestClockWitnessPast(t *testing.T) {
+	var clock LamportClock
+	clock.Witness(10)
+	if now := clock.Witness(3); now != 12 {
+		t.Errorf("Witness(3) at time 11 = %d, want 12", now)
+	}
+}
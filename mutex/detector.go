@@ -0,0 +1,143 @@
+package mutex
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	proto "MutualExclusion/grpc"
+)
+
+// missedHeartbeats is the number of heartbeats not answered after which a peer is suspected
+const missedHeartbeats = 3
+
+// detector is a timeout based failure detector: a peer that answered no
+// heartbeat, and sent none, for missedHeartbeats intervals is suspected to
+// have crashed. It is trusted again as soon as it is heard of.
+// A suspected peer can be only slow, so excluding it can break the mutual exclusion.
+type detector struct {
+	// interval between two heartbeats, zero when the detector is disabled
+	interval time.Duration
+	// mu protects all the fields below
+	mu        sync.Mutex
+	lastSeen  map[string]time.Time
+	suspected map[string]bool
+	// closed and replaced every time a peer is suspected or trusted again
+	changed chan struct{}
+}
+
+func newDetector(interval time.Duration) *detector {
+	return &detector{
+		interval:  interval,
+		lastSeen:  make(map[string]time.Time),
+		suspected: make(map[string]bool),
+		changed:   make(chan struct{}),
+	}
+}
+
+// seen records that peerRef is alive
+func (d *detector) seen(peerRef string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastSeen[peerRef] = time.Now()
+	if d.suspected[peerRef] {
+		delete(d.suspected, peerRef)
+		log.Printf("Peer [%s] is alive again", peerRef)
+		d.notify()
+	}
+}
+
+// check suspects the peers not heard of for too long, the peers not known
+// yet are given missedHeartbeats intervals to answer
+func (d *detector) check(peers map[string]proto.MutualExlusionServiceClient) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	for peerRef := range peers {
+		last, found := d.lastSeen[peerRef]
+		if !found {
+			d.lastSeen[peerRef] = now
+			continue
+		}
+		if !d.suspected[peerRef] && now.Sub(last) > missedHeartbeats*d.interval {
+			d.suspected[peerRef] = true
+			log.Printf("Peer [%s] suspected, no heartbeat since %v", peerRef, now.Sub(last).Round(time.Millisecond))
+			d.notify()
+		}
+	}
+}
+
+// notify wakes up who waits for a change, d.mu must be held
+func (d *detector) notify() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// suspects reports if peerRef is suspected to have crashed
+func (d *detector) suspects(peerRef string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.suspected[peerRef]
+}
+
+// wait returns a channel closed at the next change of the suspected peers
+func (d *detector) wait() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.changed
+}
+
+// heartbeat sends a heartbeat to every peer at every interval until done is closed
+func (n *node) heartbeat(done <-chan struct{}) {
+	ticker := time.NewTicker(n.detector.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+		peers := n.peers.snapshot()
+		for peerRef, peer := range peers {
+			go func(peerRef string, peer proto.MutualExlusionServiceClient) {
+				ctx, cancel := context.WithTimeout(context.Background(), n.detector.interval)
+				defer cancel()
+				// heartbeats don't tick the Lamport clock, they are not events of the algorithm
+				if _, err := peer.Heartbeat(ctx, &proto.Question{ClientReference: n.reference(), PeerId: n.id}); err == nil {
+					n.detector.seen(peerRef)
+				}
+			}(peerRef, peer)
+		}
+		n.detector.check(peers)
+	}
+}
+
+// alive returns the connected peers not suspected by the failure detector
+func (n *node) alive() map[string]proto.MutualExlusionServiceClient {
+	peers := n.peers.snapshot()
+	for peerRef := range peers {
+		if n.detector.suspects(peerRef) {
+			delete(peers, peerRef)
+		}
+	}
+	return peers
+}
+
+// Peers returns the "address:port" of the connected peers, true when the
+// failure detector thinks the peer is alive
+func (n *node) Peers() map[string]bool {
+	view := make(map[string]bool)
+	for peerRef := range n.peers.snapshot() {
+		view[peerRef] = !n.detector.suspects(peerRef)
+	}
+	return view
+}
+
+func (n *node) Heartbeat(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	n.detector.seen(n.sender(in.ClientReference))
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(n.clock.Now()),
+	}, nil
+}
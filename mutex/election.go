@@ -0,0 +1,326 @@
+package mutex
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// links for the election algorithms https://www.geeksforgeeks.org/election-algorithm-and-distributed-processing/
+
+// names of the election algorithms of the centralized mutex
+const (
+	Bully = "bully"
+	Ring  = "ring"
+)
+
+// electionTimeout is how long a peer waits for the answers during an election
+const electionTimeout = 2 * time.Second
+
+// The priority of a peer is its position in the members, the alive peer with
+// the highest priority becomes the coordinator.
+//
+// Bully: the peer that finds out the coordinator failed sends ELECTION to the
+// peers with higher priority. If none answers it takes over, otherwise the
+// ones that answered start their own election.
+//
+// Ring: the ELECTION message goes around the members, skipping the failed
+// ones, and collects the alive peers. When it is back to the peer that
+// started it, that peer tells the one with the highest priority to take over.
+
+// priority returns the position of peerRef in the members, -1 if it is not a member
+func (m *CentralMutex) priority(peerRef string) int {
+	for i, member := range m.memberList() {
+		if member == peerRef {
+			return i
+		}
+	}
+	return -1
+}
+
+// elect waits for a coordinator replacing failed, starting an election if needed.
+// It returns nil when a new coordinator is announced or it is time to try again.
+func (m *CentralMutex) elect(ctx context.Context, failed string) error {
+	m.mu.Lock()
+	coordinator, announced := m.coordinator, m.announced
+	m.mu.Unlock()
+	if coordinator != failed {
+		// already replaced
+		return nil
+	}
+	if coordinator != "" {
+		m.startElection()
+	}
+	select {
+	case <-announced:
+		return nil
+	case <-time.After(2 * electionTimeout):
+		if coordinator == "" {
+			// the takeover of this peer did not complete
+			m.startElection()
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startElection runs an election in background, unless one started by this peer is already running
+func (m *CentralMutex) startElection() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.electing {
+		return
+	}
+	m.electing = true
+	go func() {
+		log.Printf("Lamport %d: Started %s election", m.clock.Tick(), m.election)
+		if m.election == Ring {
+			m.ring(nil)
+		} else {
+			m.bully()
+		}
+		m.mu.Lock()
+		m.electing = false
+		m.mu.Unlock()
+	}()
+}
+
+// bully sends ELECTION to the peers with higher priority and takes over if none answers
+func (m *CentralMutex) bully() {
+	higher := make(map[string]proto.MutualExlusionServiceClient)
+	for _, member := range m.memberList() {
+		if m.priority(member) <= m.priority(m.self()) {
+			continue
+		}
+		if peer, err := m.client(member); err == nil {
+			higher[member] = peer
+		}
+	}
+	election := m.newElection(nil, false)
+	ctx, cancel := context.WithTimeout(context.Background(), electionTimeout)
+	defer cancel()
+	answers, count := multicast(ctx, higher, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		log.Printf("Lamport %d: Sent ELECTION to peer [%s]", election.Time, peerRef)
+		return peer.Elect(ctx, election)
+	})
+	bullied := false
+	for i := 0; i < count; i++ {
+		p := <-answers
+		if p.err != nil {
+			continue
+		}
+		m.clock.Witness(int(p.answer.Time))
+		log.Printf("Lamport %d: Peer [%s] answered OK, it will take over", m.clock.Now(), p.peerRef)
+		bullied = true
+	}
+	if !bullied {
+		m.takeOver()
+	}
+}
+
+// ring adds this peer to the candidates and passes ELECTION to the next alive member.
+// Once ELECTION is back to this peer, the candidate with the highest priority is elected.
+func (m *CentralMutex) ring(candidates []string) {
+	if len(candidates) > 0 && candidates[0] == m.self() {
+		winner := candidates[0]
+		for _, candidate := range candidates {
+			if m.priority(candidate) > m.priority(winner) {
+				winner = candidate
+			}
+		}
+		log.Printf("Lamport %d: Election went around the ring %v, peer [%s] won", m.clock.Now(), candidates, winner)
+		if winner == m.self() {
+			m.takeOver()
+		} else if !m.pass(winner, nil, true) {
+			log.Printf("Lamport %d: Peer [%s] no more available, elected anyway", m.clock.Now(), winner)
+		}
+		return
+	}
+	if contains(candidates, m.self()) {
+		// the peer that started the election failed
+		return
+	}
+	candidates = append(candidates, m.self())
+	members := m.memberList()
+	index := m.priority(m.self())
+	for i := 1; i < len(members); i++ {
+		next := members[(index+i+len(members))%len(members)]
+		if next != m.self() && m.pass(next, candidates, false) {
+			return
+		}
+	}
+	// no other peer is alive
+	m.takeOver()
+}
+
+// pass sends ELECTION to peerRef, it returns false if peerRef is not available
+func (m *CentralMutex) pass(peerRef string, candidates []string, elected bool) bool {
+	peer, err := m.client(peerRef)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), electionTimeout)
+		defer cancel()
+		election := m.newElection(candidates, elected)
+		log.Printf("Lamport %d: Sent ELECTION %v to peer [%s]", election.Time, candidates, peerRef)
+		var answer *proto.Answer
+		if answer, err = peer.Elect(ctx, election); err == nil {
+			m.clock.Witness(int(answer.Time))
+			return true
+		}
+	}
+	log.Printf("Lamport %d: Peer [%s] not available for the election: %v", m.clock.Now(), peerRef, err)
+	return false
+}
+
+func (m *CentralMutex) newElection(candidates []string, elected bool) *proto.Election {
+	_, epoch := m.current()
+	return &proto.Election{
+		ClientReference: m.reference(),
+		Time:            int32(m.clock.Tick()),
+		PeerId:          m.id,
+		Epoch:           int32(epoch),
+		Candidates:      candidates,
+		Elected:         elected,
+	}
+}
+
+// takeOver makes this peer the coordinator of a new epoch: the lock is given
+// to the peer that holds it and the requests are queued in the order they were made
+func (m *CentralMutex) takeOver() {
+	m.mu.Lock()
+	m.epoch++
+	epoch := m.epoch
+	// the requests received meanwhile wait for the takeover
+	m.coordinator = ""
+	var holder *centralRequest
+	queue := []*centralRequest{}
+	if m.holding {
+		holder = &centralRequest{id: m.id, time: m.mine}
+	} else if m.waiting {
+		queue = append(queue, &centralRequest{id: m.id, time: m.mine, grant: make(chan struct{})})
+	}
+	m.mu.Unlock()
+	log.Printf("Lamport %d: Taking over as coordinator of epoch %d", m.clock.Tick(), epoch)
+
+	announcement := m.newElection(nil, false)
+	announcement.Epoch = int32(epoch)
+	ctx, cancel := context.WithTimeout(context.Background(), electionTimeout)
+	defer cancel()
+	answers, count := m.broadcast(ctx, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		return peer.Coordinator(ctx, announcement)
+	})
+	for i := 0; i < count; i++ {
+		p := <-answers
+		if status.Code(p.err) == codes.FailedPrecondition {
+			log.Printf("Lamport %d: Peer [%s] knows a newer coordinator, takeover abandoned", m.clock.Now(), p.peerRef)
+			return
+		}
+		if p.err != nil {
+			log.Printf("Lamport %d: Peer [%s] not available for the takeover: %v", m.clock.Now(), p.peerRef, p.err)
+			continue
+		}
+		m.clock.Witness(int(p.answer.Time))
+		if p.answer.Reply {
+			holder = &centralRequest{id: p.answer.PeerId, time: int(p.answer.RequestTime)}
+		} else if p.answer.RequestTime > 0 {
+			queue = append(queue, &centralRequest{id: p.answer.PeerId, time: int(p.answer.RequestTime), grant: make(chan struct{})})
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		return before(queue[i].time, queue[i].id, queue[j].time, queue[j].id)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if epoch != m.epoch || m.coordinator != "" {
+		// a newer coordinator has been announced meanwhile
+		return
+	}
+	// the requests already waiting here, if this peer was the coordinator, keep waiting
+	for i, request := range queue {
+		if queued := m.find(request.id, request.time); queued != nil {
+			queue[i] = queued
+		}
+	}
+	m.coordinator = m.self()
+	m.holder = holder
+	m.queue = queue
+	if m.holder == nil {
+		m.grantNext()
+	}
+	m.announce()
+	log.Printf("Lamport %d: Coordinator of epoch %d with %d waiting requests", m.clock.Now(), epoch, len(m.queue))
+}
+
+// announce wakes up who waits for a new coordinator, m.mu must be held
+func (m *CentralMutex) announce() {
+	close(m.announced)
+	m.announced = make(chan struct{})
+}
+
+func (m *CentralMutex) Elect(ctx context.Context, in *proto.Election) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+
+	m.mu.Lock()
+	// the new coordinator must have an epoch newer than the ones known by everybody
+	if int(in.Epoch) > m.epoch {
+		m.epoch = int(in.Epoch)
+	}
+	m.mu.Unlock()
+	switch {
+	case m.election == Ring && in.Elected:
+		log.Printf("Lamport %d: Elected by peer [%s]", m.clock.Now(), peerRef)
+		go m.takeOver()
+	case m.election == Ring:
+		log.Printf("Lamport %d: Received ELECTION %v from peer [%s]", m.clock.Now(), in.Candidates, peerRef)
+		go m.ring(in.Candidates)
+	default:
+		log.Printf("Lamport %d: Received ELECTION from peer [%s], answered OK", m.clock.Now(), peerRef)
+		m.startElection()
+	}
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
+
+func (m *CentralMutex) Coordinator(ctx context.Context, in *proto.Election) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// with the same epoch the peer with the highest priority wins
+	if int(in.Epoch) < m.epoch || int(in.Epoch) == m.epoch && m.priority(peerRef) < m.priority(m.coordinator) {
+		log.Printf("Lamport %d: Refused coordinator [%s] of old epoch %d", m.clock.Now(), peerRef, in.Epoch)
+		return nil, status.Errorf(codes.FailedPrecondition, "epoch %d is older than %d", in.Epoch, m.epoch)
+	}
+	log.Printf("Lamport %d: Peer [%s] is the coordinator of epoch %d", m.clock.Now(), peerRef, in.Epoch)
+	if m.coordinator == m.self() || m.coordinator == "" {
+		// the requests waiting here will be sent to the new coordinator
+		close(m.deposed)
+		m.deposed = make(chan struct{})
+		m.holder = nil
+		m.queue = nil
+	}
+	m.epoch = int(in.Epoch)
+	m.coordinator = peerRef
+	m.announce()
+
+	answer := &proto.Answer{
+		Reply:  m.holding,
+		Time:   int32(m.clock.Tick()),
+		PeerId: m.id,
+	}
+	if m.holding || m.waiting {
+		answer.RequestTime = int32(m.mine)
+	}
+	return answer, nil
+}
@@ -0,0 +1,270 @@
+package mutex
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	proto "MutualExclusion/grpc"
+)
+
+// SWIM-style discovery https://www.cs.cornell.edu/projects/Quicksilver/public_pdfs/SWIM.pdf
+//
+// At every interval a peer exchanges its membership state with a random
+// member, so a member known by one peer is soon known by all. A member that
+// doesn't answer is suspected, and the suspicion spreads like the rest: if the
+// member doesn't refute it, raising its incarnation, before missedHeartbeats
+// intervals it is declared dead and removed from the members.
+// A new peer only needs the address of one running member, a seed.
+
+// member is what this peer knows of another member
+type member struct {
+	incarnation int
+	status      proto.Rumor_Status
+	// when the status changed
+	since time.Time
+}
+
+// gossip is the membership state spread by the SWIM-style discovery
+type gossip struct {
+	// interval between two exchanges, zero when the discovery is disabled
+	interval time.Duration
+	// "address:port" of the members contacted at start
+	seeds []string
+	// mu protects all the fields below
+	mu sync.Mutex
+	// incarnation of this peer, raised to refute a suspicion
+	incarnation int
+	members     map[string]*member
+}
+
+func newGossip(interval time.Duration, seeds []string) *gossip {
+	return &gossip{
+		interval: interval,
+		seeds:    seeds,
+		members:  make(map[string]*member),
+	}
+}
+
+// overrides reports if the rumor (incarnation, status) is newer than m:
+// a higher incarnation wins, with the same one dead wins over suspect and suspect over alive
+func (m *member) overrides(incarnation int, status proto.Rumor_Status) bool {
+	return incarnation > m.incarnation || incarnation == m.incarnation && status > m.status
+}
+
+// joinSeeds joins the members through the first seed answering, so they all
+// know this peer, and it knows them, before it takes the lock. Without it the
+// lock could be taken before the first exchange, knowing no member.
+func (n *node) joinSeeds() error {
+	var err error
+	for _, seed := range n.discovery.seeds {
+		if seed == n.self() {
+			continue
+		}
+		ctx, cancel := n.withTimeout(context.Background())
+		err = n.join(ctx, seed)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		log.Printf("Lamport %d: Could not join through the seed [%s]: %v", n.clock.Now(), seed, err)
+	}
+	if err != nil {
+		return fmt.Errorf("mutex: could not join through any seed: %w", err)
+	}
+	// the first peer, nobody to join
+	return nil
+}
+
+// gossip exchanges the membership state with a random member at every
+// interval, starting from the seeds, until done is closed
+func (n *node) gossip(done <-chan struct{}) {
+	n.discovery.mu.Lock()
+	for _, peerRef := range n.memberList() {
+		if _, found := n.discovery.members[peerRef]; !found && peerRef != n.self() {
+			n.discovery.members[peerRef] = &member{status: proto.Rumor_ALIVE, since: time.Now()}
+		}
+	}
+	n.discovery.mu.Unlock()
+	n.sortMembers()
+	for _, seed := range n.discovery.seeds {
+		if seed != n.self() {
+			n.exchange(seed)
+		}
+	}
+	ticker := time.NewTicker(n.discovery.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+		if target := n.discovery.target(); target != "" {
+			n.exchange(target)
+		}
+		n.expire()
+	}
+}
+
+// target returns a random member not dead, empty if there is none
+func (g *gossip) target() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	candidates := []string{}
+	for peerRef, m := range g.members {
+		if m.status != proto.Rumor_DEAD {
+			candidates = append(candidates, peerRef)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// exchange sends the membership state to peerRef and merges the one it
+// answers, peerRef is suspected if it doesn't answer
+func (n *node) exchange(peerRef string) {
+	peer, err := n.client(peerRef)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), n.discovery.interval)
+		defer cancel()
+		var rumors *proto.Rumors
+		// the exchanges don't tick the Lamport clock, they are not events of the algorithm
+		if rumors, err = peer.Gossip(ctx, n.rumors()); err == nil {
+			n.merge(rumors.Rumors)
+			return
+		}
+	}
+	n.discovery.mu.Lock()
+	m, found := n.discovery.members[peerRef]
+	suspected := found && m.status == proto.Rumor_ALIVE
+	if suspected {
+		m.status = proto.Rumor_SUSPECT
+		m.since = time.Now()
+	}
+	n.discovery.mu.Unlock()
+	if suspected {
+		log.Printf("Lamport %d: Member [%s] suspected by the gossip: %v", n.clock.Now(), peerRef, err)
+	}
+}
+
+// rumors returns the membership state known by this peer, itself included
+func (n *node) rumors() *proto.Rumors {
+	n.discovery.mu.Lock()
+	defer n.discovery.mu.Unlock()
+	rumors := []*proto.Rumor{{
+		Member:      n.self(),
+		Incarnation: int32(n.discovery.incarnation),
+		Status:      proto.Rumor_ALIVE,
+	}}
+	for peerRef, m := range n.discovery.members {
+		rumors = append(rumors, &proto.Rumor{
+			Member:      peerRef,
+			Incarnation: int32(m.incarnation),
+			Status:      m.status,
+		})
+	}
+	return &proto.Rumors{
+		ClientReference: n.reference(),
+		Rumors:          rumors,
+	}
+}
+
+// merge applies the rumors newer than what this peer knows, the members
+// discovered are added and the dead ones removed
+func (n *node) merge(rumors []*proto.Rumor) {
+	var added, removed []string
+	n.discovery.mu.Lock()
+	for _, rumor := range rumors {
+		incarnation := int(rumor.Incarnation)
+		if rumor.Member == n.self() {
+			if rumor.Status != proto.Rumor_ALIVE && incarnation >= n.discovery.incarnation {
+				// refutes the suspicion, the new incarnation overrides it everywhere
+				n.discovery.incarnation = incarnation + 1
+				log.Printf("Lamport %d: Refuted being %s, incarnation %d", n.clock.Now(), rumor.Status, n.discovery.incarnation)
+			}
+			continue
+		}
+		m, found := n.discovery.members[rumor.Member]
+		if found && !m.overrides(incarnation, rumor.Status) {
+			continue
+		}
+		if !found && rumor.Status == proto.Rumor_DEAD {
+			// never known alive, nothing to remove
+			n.discovery.members[rumor.Member] = &member{incarnation: incarnation, status: rumor.Status, since: time.Now()}
+			continue
+		}
+		wasDead := !found || m.status == proto.Rumor_DEAD
+		n.discovery.members[rumor.Member] = &member{incarnation: incarnation, status: rumor.Status, since: time.Now()}
+		switch {
+		case rumor.Status == proto.Rumor_DEAD:
+			removed = append(removed, rumor.Member)
+		case wasDead:
+			added = append(added, rumor.Member)
+		}
+	}
+	n.discovery.mu.Unlock()
+
+	for _, peerRef := range added {
+		log.Printf("Lamport %d: Member [%s] discovered", n.clock.Now(), peerRef)
+		n.addMember(peerRef)
+	}
+	for _, peerRef := range removed {
+		log.Printf("Lamport %d: Member [%s] is dead", n.clock.Now(), peerRef)
+		n.removeMember(peerRef)
+	}
+	if len(added) > 0 {
+		n.sortMembers()
+	}
+}
+
+// forget declares dead a member that left
+func (g *gossip) forget(peerRef string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if m, found := g.members[peerRef]; found {
+		m.status = proto.Rumor_DEAD
+		m.since = time.Now()
+	}
+}
+
+// expire declares dead the members suspected for missedHeartbeats intervals
+func (n *node) expire() {
+	var dead []string
+	n.discovery.mu.Lock()
+	for peerRef, m := range n.discovery.members {
+		if m.status == proto.Rumor_SUSPECT && time.Since(m.since) > missedHeartbeats*n.discovery.interval {
+			m.status = proto.Rumor_DEAD
+			m.since = time.Now()
+			dead = append(dead, peerRef)
+		}
+	}
+	n.discovery.mu.Unlock()
+	for _, peerRef := range dead {
+		log.Printf("Lamport %d: Member [%s] did not refute the suspicion, it is dead", n.clock.Now(), peerRef)
+		n.removeMember(peerRef)
+	}
+}
+
+// sortMembers keeps the members discovered in the same order on every peer,
+// the order of the priorities of the election
+func (n *node) sortMembers() {
+	n.membersMu.Lock()
+	defer n.membersMu.Unlock()
+	sort.Strings(n.members)
+}
+
+func (n *node) Gossip(ctx context.Context, in *proto.Rumors) (*proto.Rumors, error) {
+	if n.fixed {
+		return nil, errFixedMembers
+	}
+	n.sender(in.ClientReference)
+	n.merge(in.Rumors)
+	return n.rumors(), nil
+}
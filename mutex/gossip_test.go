@@ -0,0 +1,82 @@
+package mutex
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSeedsJoinedBeforeListenReturns(t *testing.T) {
+	ports := freePorts(t, 3)
+	lockers := make([]Locker, len(ports))
+	for i, port := range ports {
+		self := "127.0.0.1:" + strconv.Itoa(port)
+		config := Config{
+			ID:      strconv.Itoa(i),
+			Address: "127.0.0.1",
+			Port:    port,
+			Timeout: 30 * time.Second,
+			Members: []string{self},
+			// no exchange during the test, only the join
+			Gossip: time.Hour,
+		}
+		if i > 0 {
+			// every peer knows only the one started before it
+			config.Seeds = []string{"127.0.0.1:" + strconv.Itoa(ports[i-1])}
+		}
+		m := New(config)
+		if err := m.Listen(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(m.Close)
+		lockers[i] = m
+	}
+
+	for i, m := range lockers {
+		if members := m.(*Mutex).memberList(); len(members) != len(ports) {
+			t.Errorf("peer %d knows the members %v, want all the %d", i, members, len(ports))
+		}
+	}
+	contend(t, lockers, 3)
+
+	m := New(Config{Address: "127.0.0.1", Port: freePorts(t, 1)[0], Gossip: time.Hour, Seeds: []string{"127.0.0.1:1"}})
+	if err := m.Listen(); err == nil {
+		m.Close()
+		t.Error("Listen succeeded with no seed answering")
+	}
+}
+
+func TestSeedsCoordinator(t *testing.T) {
+	ports := freePorts(t, 3)
+	first := "127.0.0.1:" + strconv.Itoa(ports[0])
+	lockers := make([]Locker, len(ports))
+	for i, port := range ports {
+		self := "127.0.0.1:" + strconv.Itoa(port)
+		// like the peer command: the coordinator is the first peer, or the seed until joined
+		config := Config{
+			ID:          strconv.Itoa(i),
+			Address:     "127.0.0.1",
+			Port:        port,
+			Timeout:     30 * time.Second,
+			Members:     []string{self},
+			Coordinator: self,
+			Gossip:      time.Hour,
+		}
+		if i > 0 {
+			seed := "127.0.0.1:" + strconv.Itoa(ports[i-1])
+			config.Seeds, config.Coordinator = []string{seed}, seed
+		}
+		m := NewCentral(config)
+		if err := m.Listen(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(m.Close)
+		lockers[i] = m
+	}
+	for i, m := range lockers {
+		if coordinator, _ := m.(*CentralMutex).coordinatorOf(); coordinator != first {
+			t.Errorf("peer %d has the coordinator %s, want the first peer %s", i, coordinator, first)
+		}
+	}
+	contend(t, lockers, 3)
+}
@@ -0,0 +1,179 @@
+package mutex
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+
+	proto "MutualExclusion/grpc"
+)
+
+// With a pre-shared secret every message is signed with an HMAC-SHA256 over the
+// method, a random nonce, the wall clock and the Lamport time of the sender
+// and the message, carried in the gRPC metadata. The answer is signed with the
+// nonce of its question, so an old answer can't be replayed either.
+// A message is refused if its wall clock is more than replaySkew away from the
+// one of the receiver, or if its nonce was already seen. The nonces are
+// remembered only while their message can be accepted, and the receiver
+// doesn't need to remember anything across restarts.
+
+// metadata keys of the signature
+const (
+	nonceKey     = "mutex-nonce"
+	wallKey      = "mutex-wall"
+	timeKey      = "mutex-time"
+	signatureKey = "mutex-signature"
+)
+
+// replaySkew is how far the wall clock of the sender of a message can be from
+// the one of the receiver, the clocks of the peers must be closer than that
+const replaySkew = 30 * time.Second
+
+// errBadSignature is returned for a message not signed with the secret
+var errBadSignature = status.Error(codes.Unauthenticated, "mutex: missing or invalid signature")
+
+// errReplayed is returned for a message already received or sent too long ago
+var errReplayed = status.Error(codes.Unauthenticated, "mutex: replayed message")
+
+// replays remembers the nonces of the messages received in the last replaySkew
+type replays struct {
+	mu sync.Mutex
+	// wall clock of the sender of every nonce seen
+	seen map[string]time.Time
+	// when the nonces too old to be replayed were last forgotten
+	pruned time.Time
+}
+
+func newReplays() *replays {
+	return &replays{seen: make(map[string]time.Time)}
+}
+
+// fresh records the nonce of a message sent at the wall clock sentAt and
+// received at now, it returns false if the message is a replay or too old
+func (r *replays) fresh(nonce string, sentAt, now time.Time) bool {
+	if sentAt.Before(now.Add(-replaySkew)) || sentAt.After(now.Add(replaySkew)) {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.Sub(r.pruned) > time.Second {
+		// a message older than replaySkew is refused anyway
+		for old, at := range r.seen {
+			if at.Before(now.Add(-replaySkew)) {
+				delete(r.seen, old)
+			}
+		}
+		r.pruned = now
+	}
+	if _, found := r.seen[nonce]; found {
+		return false
+	}
+	r.seen[nonce] = sentAt
+	return true
+}
+
+// mac returns the signature of message sent on method with nonce at the wall clock wall and Lamport time lamport
+func (n *node) mac(method, nonce, wall, lamport string, message interface{}) (string, error) {
+	var data []byte
+	if m, ok := message.(protobuf.Message); ok {
+		var err error
+		if data, err = (protobuf.MarshalOptions{Deterministic: true}).Marshal(m); err != nil {
+			return "", err
+		}
+	}
+	mac := hmac.New(sha256.New, n.secret)
+	for _, part := range []string{method, nonce, wall, lamport} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// answered is the method whose signature is the one of the answer to method
+func answered(method string) string {
+	return method + "/answer"
+}
+
+// sign signs the questions sent to the others peers and checks the signature of their answers
+func (n *node) sign(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	nonce := hex.EncodeToString(random)
+	wall := strconv.FormatInt(time.Now().UnixNano(), 10)
+	lamport := strconv.Itoa(n.clock.Now())
+	signature, err := n.mac(method, nonce, wall, lamport, req)
+	if err != nil {
+		return err
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, nonceKey, nonce, wallKey, wall, timeKey, lamport, signatureKey, signature)
+	var trailer metadata.MD
+	if err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailer))...); err != nil {
+		return err
+	}
+	expected, err := n.mac(answered(method), nonce, wall, lamport, reply)
+	if err != nil {
+		return err
+	}
+	if signatures := trailer.Get(signatureKey); len(signatures) == 0 || !hmac.Equal([]byte(signatures[0]), []byte(expected)) {
+		log.Printf("Lamport %d: Refused the answer to %s from [%s], missing or invalid signature", n.clock.Now(), method, cc.Target())
+		return errBadSignature
+	}
+	return nil
+}
+
+// verify refuses the questions not signed with the secret or replayed, and signs the answers
+func (n *node) verify(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	sender := ""
+	if message, ok := req.(interface{ GetClientReference() *proto.ClientReference }); ok && message.GetClientReference() != nil {
+		ref := message.GetClientReference()
+		sender = ref.ClientAddress + ":" + strconv.Itoa(int(ref.ClientPort))
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	nonces, walls, times, signatures := md.Get(nonceKey), md.Get(wallKey), md.Get(timeKey), md.Get(signatureKey)
+	if len(nonces) == 0 || len(walls) == 0 || len(times) == 0 || len(signatures) == 0 {
+		log.Printf("Lamport %d: Refused %s from [%s], not signed", n.clock.Now(), info.FullMethod, sender)
+		return nil, errBadSignature
+	}
+	nonce, wall, lamport := nonces[0], walls[0], times[0]
+	expected, err := n.mac(info.FullMethod, nonce, wall, lamport, req)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(signatures[0]), []byte(expected)) {
+		log.Printf("Lamport %d: Refused %s from [%s], invalid signature", n.clock.Now(), info.FullMethod, sender)
+		return nil, errBadSignature
+	}
+	sentAt, err := strconv.ParseInt(wall, 10, 64)
+	if err != nil || !n.replays.fresh(nonce, time.Unix(0, sentAt), time.Now()) {
+		log.Printf("Lamport %d: Refused %s from [%s], replayed message or clock too far", n.clock.Now(), info.FullMethod, sender)
+		return nil, errReplayed
+	}
+
+	answer, err := handler(ctx, req)
+	if err != nil {
+		return answer, err
+	}
+	signature, err := n.mac(answered(info.FullMethod), nonce, wall, lamport, answer)
+	if err != nil {
+		return nil, err
+	}
+	if err := grpc.SetTrailer(ctx, metadata.Pairs(signatureKey, signature)); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
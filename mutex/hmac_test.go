@@ -0,0 +1,160 @@
+package mutex
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// signed starts a peer signing with secret and returns it with a connection
+// to it that doesn't sign anything
+func signed(t *testing.T, secret string) (*Mutex, proto.MutualExlusionServiceClient, []int) {
+	t.Helper()
+	ports := freePorts(t, 2)
+	m := New(Config{Address: "127.0.0.1", Port: ports[0], Secret: []byte(secret)})
+	if err := m.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(m.Close)
+	conn, err := grpc.Dial("127.0.0.1:"+strconv.Itoa(ports[0]), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return m, proto.NewMutualExlusionServiceClient(conn), ports
+}
+
+// question returns a request of the peer at port
+func question(port int, time int) *proto.Question {
+	return &proto.Question{
+		ClientReference: &proto.ClientReference{ClientAddress: "127.0.0.1", ClientPort: int32(port)},
+		Time:            int32(time),
+		PeerId:          "other",
+	}
+}
+
+// signature returns ctx with the metadata of in signed with the key of m, sent at wall
+func signature(t *testing.T, ctx context.Context, m *Mutex, in *proto.Question, nonce string, wall time.Time) context.Context {
+	t.Helper()
+	sentAt := strconv.FormatInt(wall.UnixNano(), 10)
+	lamport := strconv.Itoa(int(in.Time))
+	mac, err := m.mac("/proto.MutualExlusionService/AskPermission", nonce, sentAt, lamport, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, nonceKey, nonce, wallKey, sentAt, timeKey, lamport, signatureKey, mac)
+}
+
+func TestSignatureRequired(t *testing.T) {
+	m, client, ports := signed(t, "secret")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.AskPermission(ctx, question(ports[1], 1)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("question not signed: got %v, want Unauthenticated", err)
+	}
+	in := question(ports[1], 2)
+	signedCtx := signature(t, ctx, m, in, "nonce", time.Now())
+	in.Time = 3
+	if _, err := client.AskPermission(signedCtx, in); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("question changed after the signature: got %v, want Unauthenticated", err)
+	}
+}
+
+func TestReplayRefused(t *testing.T) {
+	m, client, ports := signed(t, "secret")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	in := question(ports[1], 1)
+	signedCtx := signature(t, ctx, m, in, "nonce", time.Now())
+	if _, err := client.AskPermission(signedCtx, in); err != nil {
+		t.Fatalf("signed question: %v", err)
+	}
+	if _, err := client.AskPermission(signedCtx, in); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("replayed question: got %v, want Unauthenticated", err)
+	}
+
+	// a message captured before the receiver restarted is too old
+	in = question(ports[1], 2)
+	old := signature(t, ctx, m, in, "other nonce", time.Now().Add(-time.Minute))
+	if _, err := client.AskPermission(old, in); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("question sent a minute ago: got %v, want Unauthenticated", err)
+	}
+}
+
+func TestReplaysForgotten(t *testing.T) {
+	r := newReplays()
+	now := time.Now()
+	if !r.fresh("a", now, now) || r.fresh("a", now, now) {
+		t.Fatal("nonce not recorded")
+	}
+	later := now.Add(replaySkew + 2*time.Second)
+	if !r.fresh("b", later, later) {
+		t.Fatal("new nonce refused")
+	}
+	if _, found := r.seen["a"]; found {
+		t.Error("nonce older than replaySkew still remembered")
+	}
+}
+
+func TestBadAnswerSignature(t *testing.T) {
+	ports := freePorts(t, 2)
+	// a peer answering without the secret
+	unsigned := New(Config{Address: "127.0.0.1", Port: ports[0]})
+	if err := unsigned.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer unsigned.Close()
+	m := New(Config{Address: "127.0.0.1", Port: ports[1], Secret: []byte("secret"), Timeout: 5 * time.Second})
+	if err := m.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	peer, err := m.client("127.0.0.1:" + strconv.Itoa(ports[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := peer.AskPermission(ctx, question(ports[1], 1)); err != errBadSignature {
+		t.Errorf("answer not signed: got %v, want %v", err, errBadSignature)
+	}
+
+	if err := m.Lock(context.Background()); !errors.Is(err, ErrRefused) {
+		if err == nil {
+			m.Unlock()
+		}
+		t.Errorf("Lock with a peer not signing: got %v, want ErrRefused", err)
+	}
+}
+
+func TestWrongSecretRefused(t *testing.T) {
+	ports := freePorts(t, 2)
+	lockers := make([]*Mutex, 2)
+	for i, secret := range []string{"secret", "another secret"} {
+		lockers[i] = New(Config{Address: "127.0.0.1", Port: ports[i], Secret: []byte(secret), Timeout: 5 * time.Second})
+		if err := lockers[i].Listen(); err != nil {
+			t.Fatal(err)
+		}
+		defer lockers[i].Close()
+	}
+	lockers[0].Connect("127.0.0.1", ports[1])
+	if err := lockers[0].Lock(context.Background()); !errors.Is(err, ErrRefused) {
+		if err == nil {
+			lockers[0].Unlock()
+		}
+		t.Errorf("Lock with a peer of another secret: got %v, want ErrRefused", err)
+	}
+}
@@ -0,0 +1,325 @@
+package mutex
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+
+	proto "MutualExclusion/grpc"
+)
+
+// link for Lamport algorithm https://www.geeksforgeeks.org/lamports-algorithm-for-mutual-exclusion-in-distributed-system/
+
+// lamportRequest is an entry of the request queue of a peer
+type lamportRequest struct {
+	time    int
+	id      string
+	peerRef string
+}
+
+// LamportMutex is a distributed mutex using Lamport's algorithm: every peer
+// keeps a queue of the requests ordered by (time, peer id) and a peer enters
+// the critical section when its own request is the first of its queue and
+// every other peer replied to it. Leaving the critical section is announced to
+// all the peers with a release message.
+//
+// The algorithm needs FIFO channels, which concurrent gRPC calls don't
+// guarantee: a reply could overtake a request sent before it by the same peer.
+// So a reply also carries the pending request of the answering peer, and a
+// request not newer than the last release of its peer is ignored.
+type LamportMutex struct {
+	*node
+	// mu protects queue, released, mine and changed
+	mu    sync.Mutex
+	queue []lamportRequest
+	// time of the last released request of every peer id
+	released map[string]int
+	// request of this peer, time 0 when not requesting
+	mine lamportRequest
+	// closed and replaced every time the queue changes
+	changed chan struct{}
+}
+
+// NewLamport creates a LamportMutex, call Listen and Connect before using it
+func NewLamport(config Config) *LamportMutex {
+	return &LamportMutex{
+		node:     newNode(config),
+		released: make(map[string]int),
+		changed:  make(chan struct{}),
+	}
+}
+
+// Listen opens the port to new connections and serves the gRPC service in background.
+// It returns once the port is open, so it is safe to connect to the others peers after it.
+func (m *LamportMutex) Listen() error {
+	return m.listen(m)
+}
+
+// Lock blocks until the request of this peer is the first of the queue and every peer replied to it.
+// If ctx is done or the timeout expires before, the request is released and ctx.Err()
+// or ErrTimeout is returned.
+func (m *LamportMutex) Lock(ctx context.Context) error {
+	_, err := m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, false) })
+	return err
+}
+
+// TryLock is like Lock but returns false, without waiting, when the request of
+// another peer is before the one of this peer.
+func (m *LamportMutex) TryLock(ctx context.Context) (bool, error) {
+	return m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, true) })
+}
+
+// Unlock removes the request of this peer from the queues of all the peers
+func (m *LamportMutex) Unlock() {
+	m.mustHold("")
+	defer m.exit("")
+	log.Printf("Lamport %d: Ending critical section", m.clock.Tick())
+	m.release()
+}
+
+func (m *LamportMutex) acquire(ctx context.Context, try bool) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	mine := lamportRequest{time: m.clock.Tick(), id: m.id}
+	m.mine = mine
+	m.enqueue(mine)
+	m.mu.Unlock()
+
+	question := &proto.Question{
+		ClientReference: m.reference(),
+		Time:            int32(mine.time),
+		PeerId:          m.id,
+	}
+	peers := m.alive()
+	replies, _ := multicast(ctx, peers, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		log.Printf("Lamport %d: Sent request to peer [%s]", m.clock.Tick(), peerRef)
+		return peer.LamportRequest(ctx, question)
+	})
+	pending := make(map[string]bool, len(peers))
+	for peerRef := range peers {
+		pending[peerRef] = true
+	}
+	for len(pending) > 0 {
+		var p permission
+		select {
+		case p = <-replies:
+		case <-m.detector.wait():
+			for peerRef := range pending {
+				if m.detector.suspects(peerRef) {
+					log.Printf("Lamport %d: Peer [%s] suspected, its reply is not needed", m.clock.Now(), peerRef)
+					delete(pending, peerRef)
+				}
+			}
+			m.forgetSuspected()
+			continue
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+			m.release()
+			return false, abandoned(ctx)
+		}
+		if !pending[p.peerRef] {
+			// the reply of a suspected peer
+			continue
+		}
+		delete(pending, p.peerRef)
+		if refused(p.err) {
+			log.Printf("Lamport %d: Peer [%s] refused the request: %v", m.clock.Now(), p.peerRef, p.err)
+			m.release()
+			return false, refusal(p.peerRef, p.err)
+		}
+		if p.err != nil {
+			log.Printf("Lamport %d: Peer [%s] no more available, removed from connected peers", m.clock.Now(), p.peerRef)
+			m.removePeer(p.peerRef)
+			continue
+		}
+		m.clock.Witness(int(p.answer.Time))
+		if p.answer.RequestTime > 0 {
+			// the request of the peer could still be on the way
+			m.mu.Lock()
+			m.enqueue(lamportRequest{time: int(p.answer.RequestTime), id: p.answer.PeerId, peerRef: p.peerRef})
+			m.mu.Unlock()
+		}
+		log.Printf("Lamport %d: Got reply from peer [%s]", m.clock.Now(), p.peerRef)
+	}
+
+	// wait until my request is the first of the queue
+	for {
+		suspicion := m.detector.wait()
+		m.forgetSuspected()
+		m.mu.Lock()
+		first := len(m.queue) > 0 && m.queue[0] == mine
+		changed := m.changed
+		m.mu.Unlock()
+		if first {
+			break
+		}
+		if try {
+			log.Printf("Lamport %d: Another request is before mine", m.clock.Now())
+			m.release()
+			return false, nil
+		}
+		select {
+		case <-changed:
+		case <-suspicion:
+		case <-ctx.Done():
+			log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+			m.release()
+			return false, abandoned(ctx)
+		}
+	}
+	log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+	return true, nil
+}
+
+// release removes the request of this peer from its queue and from the ones of the others peers
+func (m *LamportMutex) release() {
+	m.mu.Lock()
+	mine := m.mine
+	m.mine = lamportRequest{}
+	m.dequeue(mine.id, mine.time)
+	m.mu.Unlock()
+
+	ctx, cancel := m.withTimeout(context.Background())
+	defer cancel()
+	question := &proto.Question{
+		ClientReference: m.reference(),
+		Time:            int32(mine.time),
+		PeerId:          m.id,
+	}
+	answers, count := m.broadcast(ctx, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		log.Printf("Lamport %d: Sent release to peer [%s]", m.clock.Tick(), peerRef)
+		return peer.LamportRelease(ctx, question)
+	})
+	for i := 0; i < count; i++ {
+		p := <-answers
+		if refused(p.err) {
+			log.Printf("Lamport %d: Peer [%s] refused the release: %v", m.clock.Now(), p.peerRef, p.err)
+			continue
+		}
+		if p.err != nil {
+			log.Printf("Lamport %d: Peer [%s] no more available, removed from connected peers", m.clock.Now(), p.peerRef)
+			m.removePeer(p.peerRef)
+			continue
+		}
+		m.clock.Witness(int(p.answer.Time))
+	}
+}
+
+// removePeer closes the connection to peerRef and forgets its requests
+func (m *LamportMutex) removePeer(peerRef string) {
+	m.peers.remove(peerRef)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queue := m.queue[:0]
+	for _, request := range m.queue {
+		if request.peerRef != peerRef || request.id == m.id {
+			queue = append(queue, request)
+		}
+	}
+	m.queue = queue
+	m.notify()
+}
+
+// forgetSuspected removes the requests of the peers suspected by the failure
+// detector from the queue, like removePeer, so they don't block the others
+func (m *LamportMutex) forgetSuspected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queue := make([]lamportRequest, 0, len(m.queue))
+	for _, request := range m.queue {
+		if request.id != m.id && m.detector.suspects(request.peerRef) {
+			log.Printf("Lamport %d: Peer [%s] suspected, its request at time %d is removed from the queue", m.clock.Now(), request.peerRef, request.time)
+			continue
+		}
+		queue = append(queue, request)
+	}
+	if len(queue) != len(m.queue) {
+		m.queue = queue
+		m.notify()
+	}
+}
+
+// enqueue inserts request in the queue ordered by (time, id), m.mu must be held.
+// A peer has at most one pending request, so an older one of the same peer
+// has been released even if the release message is not arrived yet.
+func (m *LamportMutex) enqueue(request lamportRequest) {
+	if request.time <= m.released[request.id] {
+		// late request, already released
+		return
+	}
+	for i, queued := range m.queue {
+		if queued.id != request.id {
+			continue
+		}
+		if queued.time >= request.time {
+			return
+		}
+		m.queue = append(m.queue[:i], m.queue[i+1:]...)
+		m.released[queued.id] = queued.time
+		break
+	}
+	index := sort.Search(len(m.queue), func(i int) bool {
+		return before(request.time, request.id, m.queue[i].time, m.queue[i].id)
+	})
+	m.queue = append(m.queue, lamportRequest{})
+	copy(m.queue[index+1:], m.queue[index:])
+	m.queue[index] = request
+	m.notify()
+}
+
+// dequeue removes the requests of peer id up to time, m.mu must be held
+func (m *LamportMutex) dequeue(id string, time int) {
+	if time > m.released[id] {
+		m.released[id] = time
+	}
+	queue := m.queue[:0]
+	for _, request := range m.queue {
+		if request.id != id || request.time > time {
+			queue = append(queue, request)
+		}
+	}
+	m.queue = queue
+	m.notify()
+}
+
+// notify wakes up the goroutine waiting for its request to be the first, m.mu must be held
+func (m *LamportMutex) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func (m *LamportMutex) LamportRequest(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Peer [%s] requested the critical section at time %d", m.clock.Now(), peerRef, in.Time)
+
+	m.mu.Lock()
+	m.enqueue(lamportRequest{time: int(in.Time), id: in.PeerId, peerRef: peerRef})
+	answer := &proto.Answer{
+		Reply:       true,
+		Time:        int32(m.clock.Tick()),
+		RequestTime: int32(m.mine.time),
+		PeerId:      m.id,
+	}
+	m.mu.Unlock()
+	return answer, nil
+}
+
+func (m *LamportMutex) LamportRelease(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Peer [%s] released the critical section", m.clock.Now(), peerRef)
+
+	m.mu.Lock()
+	m.dequeue(in.PeerId, int(in.Time))
+	m.mu.Unlock()
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
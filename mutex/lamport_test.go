@@ -0,0 +1,43 @@
+package mutex
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	proto "MutualExclusion/grpc"
+)
+
+func TestLamportSuspectedRequest(t *testing.T) {
+	// a peer hung just after sending its request: connections are accepted
+	// by the system but nothing is answered
+	hung, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hung.Close()
+	hungPort := hung.Addr().(*net.TCPAddr).Port
+
+	m := NewLamport(Config{Address: "127.0.0.1", Port: freePorts(t, 1)[0], Heartbeat: 20 * time.Millisecond})
+	if err := m.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.Connect("127.0.0.1", hungPort)
+	_, err = m.LamportRequest(context.Background(), &proto.Question{
+		ClientReference: &proto.ClientReference{ClientAddress: "127.0.0.1", ClientPort: int32(hungPort)},
+		Time:            1,
+		PeerId:          "other",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Lock(ctx); err != nil {
+		t.Fatalf("the request of the suspected peer still blocks the queue: %v", err)
+	}
+	m.Unlock()
+}
@@ -0,0 +1,96 @@
+// Package mutex implements distributed mutual exclusion algorithms on top of
+// the MutualExlusionService gRPC service, so they can be embedded in any
+// program that needs a lock shared between several processes.
+// All the peers of a network must use the same algorithm.
+package mutex
+
+import (
+	"context"
+	"fmt"
+)
+
+// names of the algorithms accepted by NewLocker
+const (
+	RicartAgrawala = "ricart-agrawala"
+	Lamport        = "lamport"
+	SuzukiKasami   = "suzuki-kasami"
+	Maekawa        = "maekawa"
+	Raymond        = "raymond"
+	Central        = "central"
+)
+
+// Locker is a distributed mutex, whatever the algorithm behind it
+type Locker interface {
+	// Listen opens the port to new connections, call it before Connect
+	Listen() error
+	// Connect adds a peer taking part in the mutual exclusion
+	Connect(address string, port int)
+	// Disconnect removes a peer
+	Disconnect(address string, port int)
+	// Close stops the peer
+	Close()
+	// Lock blocks until this peer can enter the critical section
+	Lock(ctx context.Context) error
+	// TryLock enters the critical section only if nobody else is using or waiting for it
+	TryLock(ctx context.Context) (bool, error)
+	// Unlock leaves the critical section, it panics if this peer doesn't hold it
+	Unlock()
+	// Clock returns the Lamport clock of the peer
+	Clock() *LamportClock
+	// Peers returns the connected peers, true when the failure detector thinks they are alive
+	Peers() map[string]bool
+	// Join adds this peer to a running network through the member at address:port
+	Join(ctx context.Context, address string, port int) error
+	// Leave removes this peer from the network, then it can be closed
+	Leave(ctx context.Context) error
+	// SetMembers replaces the members of this peer only, connecting to the new
+	// ones and disconnecting from the removed ones
+	SetMembers(members []string) (added, removed []string, err error)
+}
+
+// RWLocker is a Locker that can also be held in shared mode by several peers
+// at the same time, only Ricart & Agrawala implements it
+type RWLocker interface {
+	Locker
+	// RLock blocks until this peer can enter the critical section together with the others readers
+	RLock(ctx context.Context) error
+	// TryRLock enters in shared mode only if nobody is using or waiting for the exclusive lock
+	TryRLock(ctx context.Context) (bool, error)
+	// RUnlock leaves the critical section entered with RLock
+	RUnlock()
+}
+
+// NewLocker creates a peer using the given algorithm
+func NewLocker(algorithm string, config Config) (Locker, error) {
+	if (config.Slots > 1 || len(config.ResourceSlots) > 0) && algorithm != RicartAgrawala {
+		return nil, fmt.Errorf("mutex: algorithm %q has only one slot", algorithm)
+	}
+	if config.StateLog != "" && algorithm != RicartAgrawala {
+		return nil, fmt.Errorf("mutex: algorithm %q has no state log", algorithm)
+	}
+	if config.Gossip > 0 && (algorithm == Maekawa || algorithm == Raymond) {
+		return nil, fmt.Errorf("mutex: the members of algorithm %q can't be discovered", algorithm)
+	}
+	switch algorithm {
+	case RicartAgrawala:
+		return New(config), nil
+	case Lamport:
+		return NewLamport(config), nil
+	case SuzukiKasami:
+		return NewSuzukiKasami(config), nil
+	case Maekawa:
+		m, err := NewMaekawa(config)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case Raymond:
+		return NewRaymond(config), nil
+	case Central:
+		if config.Election != "" && config.Election != Bully && config.Election != Ring {
+			return nil, fmt.Errorf("mutex: unknown election algorithm %q", config.Election)
+		}
+		return NewCentral(config), nil
+	}
+	return nil, fmt.Errorf("mutex: unknown algorithm %q", algorithm)
+}
@@ -0,0 +1,364 @@
+package mutex
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+
+	proto "MutualExclusion/grpc"
+)
+
+// link for Maekawa algorithm https://www.geeksforgeeks.org/maekawas-algorithm-for-mutual-exclusion-in-distributed-system/
+
+// maekawaRequest is a request received by a peer as a voter
+type maekawaRequest struct {
+	time    int
+	id      string
+	peerRef string
+	// FAILED has already been sent for it
+	failed bool
+}
+
+func (r maekawaRequest) before(other maekawaRequest) bool {
+	return before(r.time, r.id, other.time, other.id)
+}
+
+// MaekawaMutex is a distributed mutex using Maekawa's algorithm: a peer only
+// asks the permission of its voting set, the members in the same row and
+// column when the members are arranged in a square grid, so every entry costs
+// O(sqrt(N)) messages. Any two voting sets intersect and a member votes for one
+// request at a time, so two peers can't collect all their votes together.
+//
+// Deadlocks are avoided with the INQUIRE, RELINQUISH and FAILED messages: a
+// member that receives a request with higher priority than the one it voted
+// for inquires the voted peer, which gives the vote back if it knows it can't
+// enter yet. The algorithm needs FIFO channels, so the messages go through an
+// outbox.
+type MaekawaMutex struct {
+	*node
+	// voting set, this peer included
+	quorum []string
+	out    outbox
+	// mu protects all the fields below
+	mu sync.Mutex
+
+	// requester side
+	// time of the request of this peer, 0 when not requesting
+	mine int
+	// members that voted for the request of this peer
+	votes map[string]bool
+	// a FAILED was received for the request of this peer
+	failed bool
+	// members whose INQUIRE is waiting for a FAILED or the critical section
+	inquiries map[string]bool
+	// closed when all the votes are received, nil when not waiting for them
+	granted chan struct{}
+	// closed when a FAILED is received, nil when not waiting for it
+	refused chan struct{}
+	inside  bool
+
+	// voter side
+	// request this peer voted for, nil if the vote is free
+	voted *maekawaRequest
+	// an INQUIRE has been sent for the current vote
+	inquired bool
+	// requests waiting for the vote, ordered by (time, id)
+	queue []maekawaRequest
+}
+
+// NewMaekawa creates a MaekawaMutex, call Listen and Connect before using it.
+// config.Members must contain this peer.
+func NewMaekawa(config Config) (*MaekawaMutex, error) {
+	m := &MaekawaMutex{
+		node:      newNode(config),
+		votes:     make(map[string]bool),
+		inquiries: make(map[string]bool),
+	}
+	index := -1
+	for i, member := range config.Members {
+		if member == m.self() {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("mutex: %s is not in the members", m.self())
+	}
+	m.quorum = gridQuorum(config.Members, index)
+	m.fixed = true
+	log.Printf("Voting set: %v", m.quorum)
+	return m, nil
+}
+
+// gridQuorum arranges the members in a square grid, row by row, and returns
+// the ones in the same row or column as the member at index. Every pair of
+// voting sets built this way intersects, also when the last row is not full.
+func gridQuorum(members []string, index int) []string {
+	size := int(math.Ceil(math.Sqrt(float64(len(members)))))
+	row, column := index/size, index%size
+	quorum := []string{}
+	for i, member := range members {
+		if i/size == row || i%size == column {
+			quorum = append(quorum, member)
+		}
+	}
+	return quorum
+}
+
+// Listen opens the port to new connections and serves the gRPC service in background.
+// It returns once the port is open, so it is safe to connect to the others peers after it.
+func (m *MaekawaMutex) Listen() error {
+	return m.listen(m)
+}
+
+// Lock blocks until all the members of the voting set voted for this peer.
+// If ctx is done or the timeout expires before, the request is released and
+// ctx.Err() or ErrTimeout is returned.
+func (m *MaekawaMutex) Lock(ctx context.Context) error {
+	_, err := m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, false) })
+	return err
+}
+
+// TryLock is like Lock but returns false as soon as a member of the voting set
+// answers that it voted for a request with higher priority.
+func (m *MaekawaMutex) TryLock(ctx context.Context) (bool, error) {
+	return m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, true) })
+}
+
+// Unlock gives the votes back to the members of the voting set
+func (m *MaekawaMutex) Unlock() {
+	m.mustHold("")
+	defer m.exit("")
+	log.Printf("Lamport %d: Ending critical section", m.clock.Tick())
+	m.mu.Lock()
+	m.release()
+	m.mu.Unlock()
+}
+
+func (m *MaekawaMutex) acquire(ctx context.Context, try bool) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	m.mine = m.clock.Tick()
+	m.votes = make(map[string]bool)
+	m.inquiries = make(map[string]bool)
+	m.failed = false
+	granted := make(chan struct{})
+	m.granted = granted
+	var refused chan struct{}
+	if try {
+		refused = make(chan struct{})
+		m.refused = refused
+	}
+	for _, member := range m.quorum {
+		m.send(member, proto.Vote_REQUEST, m.mine)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-granted:
+		log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+		return true, nil
+	case <-refused:
+		log.Printf("Lamport %d: Another request has the vote", m.clock.Now())
+		return m.abandon(), nil
+	case <-ctx.Done():
+		log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+		if m.abandon() {
+			return true, nil
+		}
+		return false, abandoned(ctx)
+	}
+}
+
+// abandon releases the request of this peer, it returns true if all the votes arrived in the meantime
+func (m *MaekawaMutex) abandon() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inside {
+		log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+		return true
+	}
+	m.release()
+	return false
+}
+
+// release sends RELEASE to the voting set, the votes of a request not
+// granted yet are released too, m.mu must be held
+func (m *MaekawaMutex) release() {
+	for _, member := range m.quorum {
+		m.send(member, proto.Vote_RELEASE, m.mine)
+	}
+	m.mine = 0
+	m.inside = false
+	m.granted = nil
+	m.refused = nil
+}
+
+// send posts a message for member, m.mu must be held.
+// The messages for this peer itself are handled locally, in the same order.
+func (m *MaekawaMutex) send(member string, kind proto.Vote_Kind, requestTime int) {
+	vote := &proto.Vote{
+		ClientReference: m.reference(),
+		PeerId:          m.id,
+		Kind:            kind,
+		RequestTime:     int32(requestTime),
+	}
+	m.out.post(member, func() {
+		vote.Time = int32(m.clock.Tick())
+		if member == m.self() {
+			m.handle(member, vote)
+			return
+		}
+		log.Printf("Lamport %d: Sent %s to peer [%s]", vote.Time, kind, member)
+		peer, err := m.client(member)
+		if err == nil {
+			ctx, cancel := m.withTimeout(context.Background())
+			defer cancel()
+			var answer *proto.Answer
+			if answer, err = peer.Maekawa(ctx, vote); err == nil {
+				m.clock.Witness(int(answer.Time))
+				return
+			}
+		}
+		log.Printf("Lamport %d: Peer [%s] no more available, %s lost: %v", m.clock.Now(), member, kind, err)
+	})
+}
+
+func (m *MaekawaMutex) Maekawa(ctx context.Context, in *proto.Vote) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Received %s from peer [%s]", m.clock.Now(), in.Kind, peerRef)
+	m.handle(peerRef, in)
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
+
+// handle applies a message received from peerRef
+func (m *MaekawaMutex) handle(peerRef string, in *proto.Vote) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	request := maekawaRequest{time: int(in.RequestTime), id: in.PeerId, peerRef: peerRef}
+	switch in.Kind {
+	// messages for the voter
+	case proto.Vote_REQUEST:
+		m.request(request)
+	case proto.Vote_RELINQUISH:
+		if m.voted != nil && m.voted.id == request.id && m.voted.time == request.time {
+			// the requester knows it can't enter yet, no need to send it FAILED again
+			request.failed = true
+			m.insert(request)
+			m.voted = nil
+			m.vote()
+		}
+	case proto.Vote_RELEASE:
+		if m.voted != nil && m.voted.id == request.id && m.voted.time == request.time {
+			m.voted = nil
+			m.vote()
+			return
+		}
+		// an abandoned request still waiting for the vote
+		for i, queued := range m.queue {
+			if queued.id == request.id && queued.time == request.time {
+				m.queue = append(m.queue[:i], m.queue[i+1:]...)
+				break
+			}
+		}
+
+	// messages for the requester, about an old request when the time is not the one of mine
+	case proto.Vote_LOCKED:
+		if request.time != m.mine {
+			return
+		}
+		m.votes[peerRef] = true
+		if len(m.votes) == len(m.quorum) && m.granted != nil {
+			m.inside = true
+			close(m.granted)
+			m.granted = nil
+		}
+	case proto.Vote_FAILED:
+		if request.time != m.mine {
+			return
+		}
+		m.failed = true
+		for member := range m.inquiries {
+			m.relinquish(member)
+		}
+		m.inquiries = make(map[string]bool)
+		if m.refused != nil {
+			close(m.refused)
+			m.refused = nil
+		}
+	case proto.Vote_INQUIRE:
+		if request.time != m.mine || !m.votes[peerRef] || m.inside {
+			// the vote will come back with the release
+			return
+		}
+		if m.failed {
+			m.relinquish(peerRef)
+		} else {
+			m.inquiries[peerRef] = true
+		}
+	}
+}
+
+// relinquish gives back the vote of member, m.mu must be held
+func (m *MaekawaMutex) relinquish(member string) {
+	delete(m.votes, member)
+	m.send(member, proto.Vote_RELINQUISH, m.mine)
+}
+
+// request votes for request or queues it, m.mu must be held
+func (m *MaekawaMutex) request(request maekawaRequest) {
+	if m.voted == nil {
+		m.voted = &request
+		m.send(request.peerRef, proto.Vote_LOCKED, request.time)
+		return
+	}
+	m.insert(request)
+	// the new request has the highest priority: ask the vote back
+	if request.before(*m.voted) && m.queue[0] == request && !m.inquired {
+		m.inquired = true
+		m.send(m.voted.peerRef, proto.Vote_INQUIRE, m.voted.time)
+	}
+	m.fail()
+}
+
+// vote gives the free vote to the request with the highest priority, m.mu must be held
+func (m *MaekawaMutex) vote() {
+	m.inquired = false
+	if len(m.queue) == 0 {
+		return
+	}
+	next := m.queue[0]
+	m.queue = m.queue[1:]
+	m.voted = &next
+	m.send(next.peerRef, proto.Vote_LOCKED, next.time)
+	m.fail()
+}
+
+// fail sends FAILED to the queued requests that have not the highest priority, m.mu must be held
+func (m *MaekawaMutex) fail() {
+	for i := range m.queue {
+		if m.queue[i].failed || (i == 0 && m.queue[i].before(*m.voted)) {
+			continue
+		}
+		m.queue[i].failed = true
+		m.send(m.queue[i].peerRef, proto.Vote_FAILED, m.queue[i].time)
+	}
+}
+
+// insert adds request to the queue ordered by (time, id), m.mu must be held
+func (m *MaekawaMutex) insert(request maekawaRequest) {
+	index := sort.Search(len(m.queue), func(i int) bool {
+		return request.before(m.queue[i])
+	})
+	m.queue = append(m.queue, maekawaRequest{})
+	copy(m.queue[index+1:], m.queue[index:])
+	m.queue[index] = request
+}
@@ -0,0 +1,331 @@
+package mutex
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// errFixedMembers is returned by the algorithms that compute a structure, a
+// voting set or a tree, from the members when the peer is created
+var errFixedMembers = status.Error(codes.Unimplemented, "mutex: the members of this algorithm can't change")
+
+// errSlotMembers is returned by Join and Leave when a resource has more than
+// one slot: a peer holds only one of them, so taking the lock doesn't keep the
+// others holders out of the critical section while the members change
+var errSlotMembers = status.Error(codes.FailedPrecondition, "mutex: the members can't change with resources of more than one slot")
+
+// addMemberMethod is the gRPC method of AddMember, the only one forwarded
+const addMemberMethod = "/proto.MutualExlusionService/AddMember"
+
+// errNotForwarded is returned for a RemoveMember forwarded: a peer leaves only
+// for itself, so nobody can remove another peer
+var errNotForwarded = status.Error(codes.PermissionDenied, "mutex: only a join is forwarded")
+
+// A peer joins or leaves holding the lock, so nobody is in the critical section
+// while the members change: a peer waiting for the lock asks again the peers
+// whose permission it has not, the new one included.
+// With resources of more than one slot the members can't change.
+
+// turn returns the channel holding the turn of the lock named name
+func (n *node) turn(name string) chan struct{} {
+	n.turnsMu.Lock()
+	defer n.turnsMu.Unlock()
+	turn, found := n.turns[name]
+	if !found {
+		turn = make(chan struct{}, 1)
+		n.turns[name] = turn
+	}
+	return turn
+}
+
+// serialize runs acquire once no other goroutine of this peer wants the lock
+// named name, the turn is kept until exit is called if the lock is taken.
+// The algorithms handle one request of the peer at a time.
+func (n *node) serialize(ctx context.Context, name string, acquire func() (bool, error)) (bool, error) {
+	select {
+	case n.turn(name) <- struct{}{}:
+	case <-ctx.Done():
+		return false, abandoned(ctx)
+	}
+	entered, err := acquire()
+	if !entered {
+		n.exit(name)
+	}
+	return entered, err
+}
+
+// exit gives the turn of the lock named name to the next goroutine of this peer
+func (n *node) exit(name string) {
+	select {
+	case <-n.turn(name):
+	default:
+		panic("mutex: unlock of unlocked mutex")
+	}
+}
+
+// mustHold panics, like sync.Mutex, when the lock named name is unlocked
+// while no goroutine of this peer holds or is taking it
+func (n *node) mustHold(name string) {
+	if len(n.turn(name)) == 0 {
+		panic("mutex: unlock of unlocked mutex")
+	}
+}
+
+// memberList returns a copy of the members
+func (n *node) memberList() []string {
+	n.membersMu.Lock()
+	defer n.membersMu.Unlock()
+	return append([]string(nil), n.members...)
+}
+
+// addMember adds peerRef to the members and connects to it
+func (n *node) addMember(peerRef string) {
+	n.membersMu.Lock()
+	if !contains(n.members, peerRef) {
+		n.members = append(n.members, peerRef)
+	}
+	delete(n.removed, peerRef)
+	n.membersMu.Unlock()
+	if peerRef != n.self() {
+		n.client(peerRef)
+	}
+}
+
+// removeMember removes peerRef from the members and closes the connection to it
+func (n *node) removeMember(peerRef string) {
+	n.membersMu.Lock()
+	for i, member := range n.members {
+		if member == peerRef {
+			n.members = append(n.members[:i], n.members[i+1:]...)
+			break
+		}
+	}
+	n.membersMu.Unlock()
+	n.peers.remove(peerRef)
+}
+
+// SetMembers replaces the members with members, in their order, for
+// example when the configuration file changes. Unlike Join and Leave it
+// changes only this peer and doesn't take the lock, every peer is expected
+// to be given the same members.
+// A request waiting for the permission of a removed member stops waiting for
+// it, its connection is closed. A member added is asked by the requests still
+// waiting before they enter the critical section. A removed member is not
+// connected again when it sends a message, it is still answered.
+func (n *node) SetMembers(members []string) ([]string, []string, error) {
+	if n.fixed {
+		return nil, nil, errFixedMembers
+	}
+	var added, removed []string
+	n.membersMu.Lock()
+	for _, member := range n.members {
+		if !contains(members, member) && member != n.self() {
+			removed = append(removed, member)
+			n.removed[member] = true
+		}
+	}
+	for _, member := range members {
+		if !contains(n.members, member) && member != n.self() {
+			added = append(added, member)
+		}
+		delete(n.removed, member)
+	}
+	n.members = append([]string(nil), members...)
+	n.membersMu.Unlock()
+
+	for _, member := range removed {
+		log.Printf("Lamport %d: Peer [%s] removed from the members", n.clock.Now(), member)
+		n.peers.remove(member)
+		n.discovery.forget(member)
+	}
+	for _, member := range added {
+		log.Printf("Lamport %d: Peer [%s] added to the members", n.clock.Now(), member)
+		n.client(member)
+	}
+	return added, removed, nil
+}
+
+func (n *node) membership(forwarded bool) *proto.Membership {
+	return &proto.Membership{
+		ClientReference: n.reference(),
+		Time:            int32(n.clock.Tick()),
+		Member:          n.self(),
+		Forwarded:       forwarded,
+	}
+}
+
+// Join adds this peer to a running network through the member at
+// address:port, which takes the lock and tells the others members. This peer
+// then connects to all the members.
+func (n *node) Join(ctx context.Context, address string, port int) error {
+	if n.fixed {
+		return errFixedMembers
+	}
+	return n.join(ctx, address+":"+strconv.Itoa(port))
+}
+
+// join adds this peer to the members through the member sponsor
+func (n *node) join(ctx context.Context, sponsor string) error {
+	peer, err := n.client(sponsor)
+	if err != nil {
+		return err
+	}
+	log.Printf("Lamport %d: Asked peer [%s] to join", n.clock.Now(), sponsor)
+	members, err := peer.AddMember(ctx, n.membership(false))
+	if err != nil {
+		if !contains(n.memberList(), sponsor) {
+			n.peers.remove(sponsor)
+		}
+		return err
+	}
+	n.clock.Witness(int(members.Time))
+	for _, member := range members.Members {
+		n.addMember(member)
+	}
+	if central, ok := n.locker.(interface{ adopt(string, int) }); ok {
+		central.adopt(members.Coordinator, int(members.Epoch))
+	}
+	log.Printf("Lamport %d: Joined the members %v", n.clock.Now(), n.memberList())
+	return nil
+}
+
+// lockAll takes the lock of every resource known by this peer, in the same
+// order on every peer, so nobody is in a critical section while the members
+// change. It returns the function releasing them, or errSlotMembers if a
+// resource can have more than one holder.
+func (n *node) lockAll(ctx context.Context) (func(), error) {
+	named, ok := n.locker.(interface {
+		Resource(name string) *Resource
+		resourceNames() []string
+		multiSlot() bool
+	})
+	if ok && named.multiSlot() {
+		return nil, errSlotMembers
+	}
+	if !ok {
+		if err := n.locker.Lock(ctx); err != nil {
+			return nil, err
+		}
+		return n.locker.Unlock, nil
+	}
+	locked := []*Resource{}
+	unlock := func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			locked[i].Unlock()
+		}
+	}
+	for _, name := range named.resourceNames() {
+		r := named.Resource(name)
+		if err := r.Lock(ctx); err != nil {
+			unlock()
+			return nil, err
+		}
+		locked = append(locked, r)
+	}
+	return unlock, nil
+}
+
+// Leave removes this peer from the network: it takes the lock of every
+// resource, so it must not hold any, and tells the others members. Close it
+// afterwards, it doesn't send heartbeats anymore.
+func (n *node) Leave(ctx context.Context) error {
+	if n.fixed {
+		return errFixedMembers
+	}
+	unlock, err := n.lockAll(ctx)
+	if err != nil {
+		return err
+	}
+	if leaving, ok := n.locker.(interface{ leave() }); ok {
+		// the algorithm gives away what the others need, the token
+		leaving.leave()
+	}
+	unlock()
+	// the departure is the last message, the others could connect again to this peer otherwise
+	n.closeOnce.Do(func() { close(n.done) })
+	membership := n.membership(false)
+	answers, count := n.broadcast(ctx, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		return peer.RemoveMember(ctx, membership)
+	})
+	for i := 0; i < count; i++ {
+		p := <-answers
+		if p.err != nil {
+			log.Printf("Lamport %d: Peer [%s] not available to leave: %v", n.clock.Now(), p.peerRef, p.err)
+			continue
+		}
+		n.clock.Witness(int(p.answer.Time))
+	}
+	log.Printf("Lamport %d: Left the members", n.clock.Now())
+	return nil
+}
+
+func (n *node) AddMember(ctx context.Context, in *proto.Membership) (*proto.Members, error) {
+	n.clock.Witness(int(in.Time))
+	if n.fixed {
+		return nil, errFixedMembers
+	}
+	if in.Forwarded {
+		log.Printf("Lamport %d: Peer [%s] joined", n.clock.Now(), in.Member)
+		n.addMember(in.Member)
+		return &proto.Members{Time: int32(n.clock.Tick())}, nil
+	}
+
+	log.Printf("Lamport %d: Peer [%s] asked to join", n.clock.Now(), in.Member)
+	unlock, err := n.lockAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	forward := n.membership(true)
+	forward.Member = in.Member
+	answers, count := n.broadcast(ctx, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		members, err := peer.AddMember(ctx, forward)
+		if err != nil {
+			return nil, err
+		}
+		return &proto.Answer{Reply: true, Time: members.Time}, nil
+	})
+	for i := 0; i < count; i++ {
+		p := <-answers
+		if p.err != nil {
+			log.Printf("Lamport %d: Peer [%s] not available for the join: %v", n.clock.Now(), p.peerRef, p.err)
+			continue
+		}
+		n.clock.Witness(int(p.answer.Time))
+	}
+	n.addMember(in.Member)
+	log.Printf("Lamport %d: Peer [%s] joined", n.clock.Now(), in.Member)
+	joined := &proto.Members{
+		Time:    int32(n.clock.Tick()),
+		Members: n.memberList(),
+	}
+	if central, ok := n.locker.(interface{ coordinatorOf() (string, int) }); ok {
+		coordinator, epoch := central.coordinatorOf()
+		joined.Coordinator, joined.Epoch = coordinator, int32(epoch)
+	}
+	return joined, nil
+}
+
+func (n *node) RemoveMember(ctx context.Context, in *proto.Membership) (*proto.Answer, error) {
+	n.clock.Witness(int(in.Time))
+	if n.fixed {
+		return nil, errFixedMembers
+	}
+	if in.Forwarded {
+		log.Printf("Lamport %d: Refused the departure of [%s] forwarded by [%s]", n.clock.Now(), in.Member, n.sender(in.ClientReference))
+		return nil, errNotForwarded
+	}
+	log.Printf("Lamport %d: Peer [%s] left", n.clock.Now(), in.Member)
+	n.removeMember(in.Member)
+	n.discovery.forget(in.Member)
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(n.clock.Tick()),
+	}, nil
+}
@@ -0,0 +1,582 @@
+package mutex
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	proto "MutualExclusion/grpc"
+)
+
+// link for Ricart & Agrawala algorithm https://www.geeksforgeeks.org/ricart-agrawala-algorithm-in-mutual-exclusion-in-distributed-system/
+
+// peer states
+const (
+	Released int = 0
+	Wanted       = 1
+	Held         = 2
+)
+
+// deferredReply is a request of another peer whose answer is delayed until
+// this peer leaves the critical section
+type deferredReply struct {
+	peerRef string
+	// closed when the peer can be authorized
+	grant chan struct{}
+}
+
+// resource is the state of one named lock
+type resource struct {
+	// number of peers that can be in the critical section at the same time
+	slots int
+	// state of the distributed mutex
+	state int
+	// mode of the lock requested or held
+	mode proto.Question_Mode
+	// lamport time of this peers request
+	requestTime int
+	// requests answered when this peer releases the critical section
+	deferred []deferredReply
+	// peers whose permission is kept from a previous request, with the mode
+	// of that request: a shared permission is not enough for an exclusive request
+	granted map[string]proto.Question_Mode
+}
+
+// Mutex is a distributed mutex using the Ricart & Agrawala algorithm, shared
+// by all the peers it is connected to.
+// It is also the gRPC server answering the requests of the other peers.
+//
+// With the Roucairol–Carvalho optimization a permission received is kept
+// until the peer that gave it asks for the critical section, so entering again
+// without contention needs no message.
+//
+// With more than one slot it is the k-mutual exclusion extension of the
+// algorithm: a peer enters with the permission of N-k peers, the requests not
+// answered yet are withdrawn. The permissions are not kept in this case.
+//
+// It is also a readers–writers lock: the shared requests (RLock) don't
+// conflict with each other, so they get the permission while another peer
+// holds or waits for the lock in shared mode.
+//
+// Lock and the others methods use the resource with the empty name, Resource
+// gives the others named locks: every resource has its own state, so the
+// contention on one of them doesn't block the others.
+//
+// With a state log the clock and the state changes are written on disk before
+// the messages are sent, so a restarted peer goes on with a clock not lower
+// than before the crash and sends again the answers it deferred.
+type Mutex struct {
+	*node
+	// path of the state log, empty when not used
+	stateLogPath string
+	wal          *stateLog
+	// number of slots of the resources, default for the ones not in resourceSlots
+	slots         int
+	resourceSlots map[string]int
+	// mu protects resources
+	mu sync.Mutex
+	// state of the resources used by this peer or the others, by name
+	resources map[string]*resource
+}
+
+// New creates a Mutex, call Listen and Connect before using it
+func New(config Config) *Mutex {
+	return &Mutex{
+		node:          newNode(config),
+		stateLogPath:  config.StateLog,
+		slots:         config.Slots,
+		resourceSlots: config.ResourceSlots,
+		resources:     make(map[string]*resource),
+	}
+}
+
+// Listen opens the port to new connections and serves the gRPC service in background.
+// It returns once the port is open, so it is safe to connect to the others peers after it.
+// With a state log, the log is replayed first.
+func (m *Mutex) Listen() error {
+	var deferred []walRecord
+	if m.stateLogPath != "" {
+		var err error
+		if deferred, err = m.recover(); err != nil {
+			return err
+		}
+	}
+	if err := m.listen(m); err != nil {
+		return err
+	}
+	if len(deferred) > 0 {
+		go m.regrant(deferred)
+	}
+	return nil
+}
+
+// recover replays the state log: the clock goes on from the last time
+// recorded. The requests in progress at the crash are released, the program
+// that made them died with it. The answers deferred by this peer are
+// returned, to send them again.
+func (m *Mutex) recover() ([]walRecord, error) {
+	wal, state, err := openStateLog(m.stateLogPath)
+	if err != nil {
+		return nil, fmt.Errorf("mutex: could not recover the state log: %w", err)
+	}
+	m.wal = wal
+	m.clock.Witness(state.clock)
+	log.Printf("Lamport %d: Recovered the state log %s", m.clock.Now(), m.stateLogPath)
+	deferred := []walRecord{}
+	for name, r := range state.resources {
+		if r.state != Released {
+			log.Printf("Lamport %d: Request made at %d abandoned by the crash%s", m.clock.Now(), r.requestTime, on(name))
+			m.wal.record(walRecord{Clock: m.clock.Now(), Op: walRelease, Resource: name})
+		}
+		deferred = append(deferred, r.deferred...)
+	}
+	return deferred, nil
+}
+
+// regrant sends again the answers deferred before the crash, the requests
+// are not released anymore. A peer still waiting for one keeps it as a
+// permission, like the ones received with Reply true.
+func (m *Mutex) regrant(deferred []walRecord) {
+	for _, reply := range deferred {
+		now := m.clock.Tick()
+		// answered or lost, it is not sent again after another crash
+		m.wal.record(walRecord{Clock: now, Op: walAnswer, Resource: reply.Resource, Peer: reply.Peer})
+		peer, err := m.client(reply.Peer)
+		if err == nil {
+			ctx, cancel := m.withTimeout(context.Background())
+			var answer *proto.Answer
+			answer, err = peer.GivePermission(ctx, &proto.Permission{
+				ClientReference: m.reference(),
+				Time:            int32(now),
+				RequestTime:     int32(reply.Time),
+				Mode:            proto.Question_Mode(reply.Mode),
+				Resource:        reply.Resource,
+			})
+			cancel()
+			if err == nil {
+				m.clock.Witness(int(answer.Time))
+			}
+		}
+		if err != nil {
+			log.Printf("Lamport %d: Answer deferred before the crash to peer [%s] lost: %v", m.clock.Now(), reply.Peer, err)
+			continue
+		}
+		log.Printf("Lamport %d: Sent again the answer deferred before the crash to peer [%s]%s", m.clock.Now(), reply.Peer, on(reply.Resource))
+	}
+}
+
+// Close stops the peer and closes the state log
+func (m *Mutex) Close() {
+	m.node.Close()
+	m.wal.close()
+}
+
+// Lock blocks until every peer gave its permission to enter the critical section.
+// If ctx is done or the timeout expires before, the request is abandoned: the
+// pending requests are cancelled, so the peers forget about them, and ctx.Err()
+// or ErrTimeout is returned.
+func (m *Mutex) Lock(ctx context.Context) error {
+	return m.Resource("").Lock(ctx)
+}
+
+// TryLock is like Lock but returns false, without waiting, when another peer
+// holds the critical section, or is waiting for it with an earlier request
+// (lower time, or same time and lower id).
+func (m *Mutex) TryLock(ctx context.Context) (bool, error) {
+	return m.Resource("").TryLock(ctx)
+}
+
+// RLock is like Lock but in shared mode: the others peers can hold the lock in
+// shared mode at the same time, not in exclusive mode.
+func (m *Mutex) RLock(ctx context.Context) error {
+	return m.Resource("").RLock(ctx)
+}
+
+// TryRLock is like TryLock but in shared mode
+func (m *Mutex) TryRLock(ctx context.Context) (bool, error) {
+	return m.Resource("").TryRLock(ctx)
+}
+
+// RUnlock releases the lock held in shared mode
+func (m *Mutex) RUnlock() {
+	m.Resource("").RUnlock()
+}
+
+// Unlock releases the critical section, the requests deferred meanwhile are answered
+func (m *Mutex) Unlock() {
+	m.Resource("").Unlock()
+}
+
+// Resource returns the lock named name, the peers using the same name share it
+func (m *Mutex) Resource(name string) *Resource {
+	return &Resource{m: m, name: name}
+}
+
+// resourceNames returns the names of the resources used by this peer or the
+// others, sorted, the default one included
+func (m *Mutex) resourceNames() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resource("")
+	names := make([]string, 0, len(m.resources))
+	for name := range m.resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// multiSlot reports if some resources have more than one slot
+func (m *Mutex) multiSlot() bool {
+	if m.slots > 1 {
+		return true
+	}
+	for _, slots := range m.resourceSlots {
+		if slots > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// resource returns the state of the resource named name, m.mu must be held
+func (m *Mutex) resource(name string) *resource {
+	r, found := m.resources[name]
+	if !found {
+		r = &resource{
+			slots:   m.slots,
+			state:   Released,
+			granted: make(map[string]proto.Question_Mode),
+		}
+		if slots, found := m.resourceSlots[name]; found {
+			r.slots = slots
+		}
+		if r.slots < 1 {
+			r.slots = 1
+		}
+		m.resources[name] = r
+	}
+	return r
+}
+
+// release sets the state of the resource to Released and answers all the
+// deferred requests, the permissions of the answered peers are given up
+func (m *Mutex) release(name string) {
+	m.mu.Lock()
+	r := m.resource(name)
+	r.state = Released
+	deferred := r.deferred
+	r.deferred = nil
+	for _, reply := range deferred {
+		delete(r.granted, reply.peerRef)
+	}
+	m.mu.Unlock()
+
+	m.wal.record(walRecord{Clock: m.clock.Now(), Op: walRelease, Resource: name})
+	for _, reply := range deferred {
+		close(reply.grant)
+	}
+}
+
+// forget removes a deferred request whose peer is no more waiting for the answer
+func (m *Mutex) forget(name string, grant chan struct{}) {
+	m.mu.Lock()
+	r := m.resource(name)
+	for i, reply := range r.deferred {
+		if reply.grant == grant {
+			r.deferred = append(r.deferred[:i], r.deferred[i+1:]...)
+			m.mu.Unlock()
+			m.wal.record(walRecord{Clock: m.clock.Now(), Op: walAnswer, Resource: name, Peer: reply.peerRef})
+			return
+		}
+	}
+	m.mu.Unlock()
+}
+
+func (m *Mutex) acquire(ctx context.Context, name string, try bool, mode proto.Question_Mode) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	r := m.resource(name)
+	requestTime := m.clock.Tick() // an event occurred
+	r.requestTime = requestTime
+	r.state = Wanted
+	r.mode = mode
+	slots := r.slots
+	m.mu.Unlock()
+	if err := m.wal.write(walRecord{Clock: requestTime, Op: walRequest, Resource: name, Time: requestTime, Mode: int(mode)}); err != nil {
+		// the requests deferred meanwhile are answered
+		m.release(name)
+		return false, fmt.Errorf("mutex: could not write the state log: %w", err)
+	}
+
+	// Peers enters the critical section if it has received the REPLY message from all other sites.
+	question := &proto.Question{
+		ClientReference: m.reference(),
+		Time:            int32(requestTime),
+		TryLock:         try,
+		PeerId:          m.id,
+		Mode:            mode,
+		Resource:        name,
+	}
+	if slots > 1 {
+		return m.acquireSlot(ctx, question, slots)
+	}
+	for {
+		// only the permissions not kept are asked, a kept one can be given up
+		// meanwhile to a request with higher priority, so check again after
+		m.mu.Lock()
+		missing := make(map[string]proto.MutualExlusionServiceClient)
+		for peerRef, peer := range m.alive() {
+			if kept, found := r.granted[peerRef]; !found || (kept == proto.Question_SHARED && mode == proto.Question_EXCLUSIVE) {
+				missing[peerRef] = peer
+			}
+		}
+		if len(missing) == 0 {
+			r.state = Held
+			m.mu.Unlock()
+			m.wal.record(walRecord{Clock: m.clock.Now(), Op: walHeld, Resource: name})
+			break
+		}
+		m.mu.Unlock()
+		if entered, err := m.ask(ctx, question, missing); !entered {
+			return false, err
+		}
+	}
+	log.Printf("Lamport %d: Starting critical section%s", m.clock.Tick(), on(name))
+	return true, nil
+}
+
+// ask sends the request to peers and waits for their permissions, it returns
+// false when a permission is denied or ctx is done, the request is then released.
+// The permission of a peer suspected meanwhile by the failure detector is not waited for.
+func (m *Mutex) ask(ctx context.Context, question *proto.Question, peers map[string]proto.MutualExlusionServiceClient) (bool, error) {
+	permissions, _ := multicast(ctx, peers, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		log.Printf("Lamport %d: Asked Peer [%s] for permission", m.clock.Tick(), peerRef)
+		return peer.AskPermission(ctx, question)
+	})
+
+	pending := make(map[string]bool, len(peers))
+	for peerRef := range peers {
+		pending[peerRef] = true
+	}
+	for len(pending) > 0 {
+		var p permission
+		select {
+		case p = <-permissions:
+		case <-m.detector.wait():
+			for peerRef := range pending {
+				if m.detector.suspects(peerRef) {
+					log.Printf("Lamport %d: Peer [%s] suspected, its permission is not needed", m.clock.Now(), peerRef)
+					delete(pending, peerRef)
+				}
+			}
+			continue
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+			m.release(question.Resource)
+			return false, abandoned(ctx)
+		}
+		if !pending[p.peerRef] {
+			// the answer of a suspected peer
+			continue
+		}
+		delete(pending, p.peerRef)
+		if refused(p.err) {
+			log.Printf("Lamport %d: Peer [%s] refused the request: %v", m.clock.Now(), p.peerRef, p.err)
+			m.release(question.Resource)
+			return false, refusal(p.peerRef, p.err)
+		}
+		if p.err != nil && m.isRemoved(p.peerRef) {
+			log.Printf("Lamport %d: Peer [%s] removed from the members, its permission is not needed", m.clock.Now(), p.peerRef)
+			continue
+		}
+		if p.err != nil {
+			log.Printf("Lamport %d: Peer [%s] no more available, removed from connected peers", m.clock.Now(), p.peerRef)
+			m.peers.remove(p.peerRef)
+			continue
+		}
+		m.clock.Witness(int(p.answer.Time))
+		if !p.answer.Reply {
+			log.Printf("Lamport %d: Peer [%s] denied the permission", m.clock.Now(), p.peerRef)
+			m.release(question.Resource)
+			return false, nil
+		}
+		m.mu.Lock()
+		m.resource(question.Resource).granted[p.peerRef] = question.Mode
+		m.mu.Unlock()
+		log.Printf("Lamport %d: Got permission from peer [%s]", m.clock.Now(), p.peerRef)
+	}
+	return true, nil
+}
+
+// acquireSlot sends the request to every peer and enters as soon as N-k
+// permissions are received, the others k-1 peers can be in the critical
+// section. The requests still deferred are withdrawn when it returns.
+func (m *Mutex) acquireSlot(ctx context.Context, question *proto.Question, slots int) (bool, error) {
+	peers := m.alive()
+	needed := len(peers) - (slots - 1)
+	permissions, _ := multicast(ctx, peers, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		log.Printf("Lamport %d: Asked Peer [%s] for permission", m.clock.Tick(), peerRef)
+		return peer.AskPermission(ctx, question)
+	})
+
+	pending := make(map[string]bool, len(peers))
+	for peerRef := range peers {
+		pending[peerRef] = true
+	}
+	granted, denied := 0, 0
+	for len(pending) > 0 && granted < needed {
+		var p permission
+		select {
+		case p = <-permissions:
+		case <-m.detector.wait():
+			// a suspected peer is not in the critical section
+			for peerRef := range pending {
+				if m.detector.suspects(peerRef) {
+					log.Printf("Lamport %d: Peer [%s] suspected, its permission is not needed", m.clock.Now(), peerRef)
+					delete(pending, peerRef)
+					granted++
+				}
+			}
+			continue
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+			m.release(question.Resource)
+			return false, abandoned(ctx)
+		}
+		if !pending[p.peerRef] {
+			continue
+		}
+		delete(pending, p.peerRef)
+		if refused(p.err) {
+			log.Printf("Lamport %d: Peer [%s] refused the request: %v", m.clock.Now(), p.peerRef, p.err)
+			m.release(question.Resource)
+			return false, refusal(p.peerRef, p.err)
+		}
+		if p.err != nil {
+			// a peer not available is not in the critical section
+			log.Printf("Lamport %d: Peer [%s] no more available, removed from connected peers", m.clock.Now(), p.peerRef)
+			m.peers.remove(p.peerRef)
+			granted++
+			continue
+		}
+		m.clock.Witness(int(p.answer.Time))
+		if !p.answer.Reply {
+			log.Printf("Lamport %d: Peer [%s] denied the permission", m.clock.Now(), p.peerRef)
+			denied++
+			if denied > slots-1 {
+				// all the slots are taken
+				m.release(question.Resource)
+				return false, nil
+			}
+			continue
+		}
+		granted++
+		log.Printf("Lamport %d: Got permission from peer [%s]", m.clock.Now(), p.peerRef)
+	}
+	m.mu.Lock()
+	m.resource(question.Resource).state = Held
+	m.mu.Unlock()
+	m.wal.record(walRecord{Clock: m.clock.Now(), Op: walHeld, Resource: question.Resource})
+	log.Printf("Lamport %d: Starting critical section%s", m.clock.Tick(), on(question.Resource))
+	return true, nil
+}
+
+func (m *Mutex) AskPermission(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Peer [%s] asked for a mutual exection%s", m.clock.Now(), peerRef, on(in.Resource))
+	// Ricart–Agrawala Algorithm
+	m.mu.Lock()
+	r := m.resource(in.Resource)
+	// two shared requests don't conflict
+	conflict := r.mode == proto.Question_EXCLUSIVE || in.Mode == proto.Question_EXCLUSIVE
+	if conflict && ((r.state == Held) || (r.state == Wanted && before(r.requestTime, m.id, int(in.Time), in.PeerId))) {
+		if in.TryLock {
+			m.mu.Unlock()
+			log.Printf("Lamport %d: Peer [%s] denied to do mutual exection", m.clock.Now(), peerRef)
+			return m.answer(false, in.Resource, peerRef)
+		}
+		// queue the reply, it is sent when i'm done
+		reply := deferredReply{peerRef: peerRef, grant: make(chan struct{})}
+		r.deferred = append(r.deferred, reply)
+		m.mu.Unlock()
+		m.wal.record(walRecord{Clock: m.clock.Now(), Op: walDefer, Resource: in.Resource, Peer: peerRef, Time: int(in.Time), Mode: int(in.Mode)})
+		log.Printf("Lamport %d: Peer [%s] deferred until the end of my critical section", m.clock.Now(), peerRef)
+		select {
+		case <-reply.grant:
+		case <-ctx.Done():
+			// the requesting peer gave up, nothing to answer
+			m.forget(in.Resource, reply.grant)
+			log.Printf("Lamport %d: Peer [%s] abandoned its request", m.clock.Now(), peerRef)
+			return nil, ctx.Err()
+		}
+	} else {
+		delete(r.granted, peerRef)
+		m.mu.Unlock()
+	}
+	log.Printf("Lamport %d: Peer [%s] authorized to do mutual exection", m.clock.Now(), peerRef)
+	return m.answer(true, in.Resource, peerRef)
+}
+
+// GivePermission receives the answer to a request of this peer deferred by a
+// peer before it crashed, it is kept as a permission if the request is still
+// in progress. The permissions are not kept with more than one slot.
+func (m *Mutex) GivePermission(ctx context.Context, in *proto.Permission) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	m.mu.Lock()
+	r := m.resource(in.Resource)
+	kept := r.state != Released && r.requestTime == int(in.RequestTime) && r.slots == 1
+	if kept {
+		r.granted[peerRef] = in.Mode
+	}
+	m.mu.Unlock()
+	if kept {
+		log.Printf("Lamport %d: Peer [%s] restarted and gave the permission deferred before its crash%s", m.clock.Now(), peerRef, on(in.Resource))
+	} else {
+		log.Printf("Lamport %d: Peer [%s] restarted and gave the permission of an old request%s", m.clock.Now(), peerRef, on(in.Resource))
+	}
+	return &proto.Answer{
+		Reply: kept,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
+
+// answer builds the answer to peerRef, its time is on disk before it is sent
+func (m *Mutex) answer(reply bool, name string, peerRef string) (*proto.Answer, error) {
+	now := m.clock.Tick()
+	if err := m.wal.write(walRecord{Clock: now, Op: walAnswer, Resource: name, Peer: peerRef}); err != nil {
+		return nil, err
+	}
+	return &proto.Answer{
+		Reply: reply,
+		Time:  int32(now),
+	}, nil
+}
+
+// on names the resource in the logs, nothing for the default one
+func on(name string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf(" on resource %q", name)
+}
+
+// before reports if the request made at time t1 by peer id1 has priority over
+// the one made at t2 by id2. Ties on the Lamport time are broken by the peer
+// identifier, so two peers always agree on the order of their requests.
+func before(t1 int, id1 string, t2 int, id2 string) bool {
+	if t1 != t2 {
+		return t1 < t2
+	}
+	return id1 < id2
+}
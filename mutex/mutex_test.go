@@ -0,0 +1,270 @@
+package mutex
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// every message is logged, too much for the tests
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// freePorts returns n ports of the loopback free at the moment
+func freePorts(t *testing.T, n int) []int {
+	t.Helper()
+	ports := make([]int, 0, n)
+	listeners := make([]net.Listener, 0, n)
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		listeners = append(listeners, listener)
+		ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+	}
+	for _, listener := range listeners {
+		listener.Close()
+	}
+	return ports
+}
+
+// cluster starts n in-process peers of algorithm on the loopback, connected
+// to each other like the peer command does with confFile.csv
+func cluster(t *testing.T, algorithm string, n int) []Locker {
+	t.Helper()
+	return clusterWith(t, algorithm, n, nil)
+}
+
+// clusterWith is cluster with the configuration of every peer changed by configure
+func clusterWith(t *testing.T, algorithm string, n int, configure func(*Config)) []Locker {
+	t.Helper()
+	ports := freePorts(t, n)
+	members := make([]string, n)
+	for i, port := range ports {
+		members[i] = "127.0.0.1:" + strconv.Itoa(port)
+	}
+	lockers := make([]Locker, n)
+	for i, port := range ports {
+		parent := ""
+		if i > 0 {
+			// binary tree in the order of the members
+			parent = members[(i-1)/2]
+		}
+		config := Config{
+			ID:          strconv.Itoa(i),
+			Address:     "127.0.0.1",
+			Port:        port,
+			Timeout:     30 * time.Second,
+			Members:     members,
+			Parent:      parent,
+			Coordinator: members[0],
+			Token:       i == 0,
+		}
+		if configure != nil {
+			configure(&config)
+		}
+		m, err := NewLocker(algorithm, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Listen(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(m.Close)
+		lockers[i] = m
+	}
+	for i, m := range lockers {
+		for j, port := range ports {
+			if i != j {
+				m.Connect("127.0.0.1", port)
+			}
+		}
+	}
+	return lockers
+}
+
+// contend makes every locker enter the critical section rounds times and
+// fails if two of them are inside at the same time
+func contend(t *testing.T, lockers []Locker, rounds int) {
+	t.Helper()
+	var inside, entries int32
+	var wg sync.WaitGroup
+	for i, m := range lockers {
+		wg.Add(1)
+		go func(i int, m Locker) {
+			defer wg.Done()
+			for round := 0; round < rounds; round++ {
+				if err := m.Lock(context.Background()); err != nil {
+					t.Errorf("peer %d: Lock: %v", i, err)
+					return
+				}
+				if holders := atomic.AddInt32(&inside, 1); holders > 1 {
+					t.Errorf("peer %d: %d peers in the critical section", i, holders)
+				}
+				atomic.AddInt32(&entries, 1)
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&inside, -1)
+				m.Unlock()
+			}
+		}(i, m)
+	}
+	wg.Wait()
+	if want := int32(len(lockers) * rounds); entries != want {
+		t.Errorf("%d entries in the critical section, want %d", entries, want)
+	}
+}
+
+var algorithms = []string{RicartAgrawala, Lamport, SuzukiKasami, Maekawa, Raymond, Central}
+
+func TestMutualExclusion(t *testing.T) {
+	for _, algorithm := range algorithms {
+		algorithm := algorithm
+		t.Run(algorithm, func(t *testing.T) {
+			contend(t, cluster(t, algorithm, 4), 5)
+		})
+	}
+}
+
+func TestTryLockHeld(t *testing.T) {
+	for _, algorithm := range algorithms {
+		algorithm := algorithm
+		t.Run(algorithm, func(t *testing.T) {
+			lockers := cluster(t, algorithm, 3)
+			if err := lockers[0].Lock(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			ok, err := lockers[1].TryLock(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok {
+				lockers[1].Unlock()
+				t.Error("TryLock succeeded while another peer holds the lock")
+			}
+			lockers[0].Unlock()
+		})
+	}
+}
+
+func TestUnlockUnlocked(t *testing.T) {
+	for _, algorithm := range algorithms {
+		algorithm := algorithm
+		t.Run(algorithm, func(t *testing.T) {
+			lockers := cluster(t, algorithm, 2)
+			if err := lockers[0].Lock(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer lockers[0].Unlock()
+			ok, err := lockers[1].TryLock(context.Background())
+			if err != nil || ok {
+				t.Fatalf("TryLock = %v, %v while another peer holds the lock", ok, err)
+			}
+			defer func() {
+				if recover() == nil {
+					t.Error("Unlock after a failed TryLock didn't panic")
+				}
+			}()
+			lockers[1].Unlock()
+		})
+	}
+}
+
+func TestLeaveWaitsForEveryResource(t *testing.T) {
+	lockers := cluster(t, RicartAgrawala, 3)
+	db := lockers[1].(*Mutex).Resource("db")
+	if err := db.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	left := make(chan error, 1)
+	go func() { left <- lockers[2].Leave(context.Background()) }()
+	select {
+	case err := <-left:
+		t.Fatalf("left while another peer holds a resource: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	db.Unlock()
+	select {
+	case err := <-left:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Leave still waiting after the resource was released")
+	}
+}
+
+func TestLeaveRefusedWithSlots(t *testing.T) {
+	lockers := clusterWith(t, RicartAgrawala, 2, func(config *Config) { config.Slots = 2 })
+	if err := lockers[1].Leave(context.Background()); err != errSlotMembers {
+		t.Errorf("Leave with 2 slots: got %v, want %v", err, errSlotMembers)
+	}
+	lockers = clusterWith(t, RicartAgrawala, 2, func(config *Config) {
+		config.ResourceSlots = map[string]int{"pool": 2}
+	})
+	if err := lockers[1].Leave(context.Background()); err != errSlotMembers {
+		t.Errorf("Leave with a resource of 2 slots: got %v, want %v", err, errSlotMembers)
+	}
+}
+
+func TestReentryWithoutMessages(t *testing.T) {
+	lockers := cluster(t, RicartAgrawala, 3)
+	if err := lockers[0].Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lockers[0].Unlock()
+	// every message received moves the clock of the others peers
+	before := []int{lockers[1].Clock().Now(), lockers[2].Clock().Now()}
+	if err := lockers[0].Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lockers[0].Unlock()
+	after := []int{lockers[1].Clock().Now(), lockers[2].Clock().Now()}
+	if before[0] != after[0] || before[1] != after[1] {
+		t.Errorf("clocks of the others peers moved from %v to %v, the permissions kept were asked again", before, after)
+	}
+}
+
+func TestReadersTogether(t *testing.T) {
+	lockers := cluster(t, RicartAgrawala, 3)
+	readers := []*Mutex{lockers[0].(*Mutex), lockers[1].(*Mutex)}
+	if err := readers[0].RLock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := readers[1].TryRLock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("TryRLock failed while only a reader holds the lock")
+	}
+
+	locked := make(chan error, 1)
+	go func() { locked <- lockers[2].Lock(context.Background()) }()
+	for _, reader := range readers {
+		select {
+		case err := <-locked:
+			t.Fatalf("writer entered with readers inside: %v", err)
+		case <-time.After(200 * time.Millisecond):
+		}
+		reader.RUnlock()
+	}
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("writer still waiting after both readers left")
+	}
+	lockers[2].Unlock()
+}
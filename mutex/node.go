@@ -0,0 +1,341 @@
+package mutex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// ErrTimeout is returned when the permission of all the peers was not received within the timeout
+var ErrTimeout = errors.New("mutex: timed out waiting for the permission of the peers")
+
+// ErrRefused is returned when a peer refused the request, for a bad signature
+// or certificate: the peer is still there and could be in the critical section
+var ErrRefused = errors.New("mutex: request refused by a peer")
+
+// Config holds the values needed to create a peer, whatever the algorithm
+type Config struct {
+	// ID must be different for every peer, it orders the requests made at the same Lamport time.
+	// When empty "address:port" is used.
+	ID string
+	// name of the peer, only used to be recognized by the others
+	Name string
+	// address and port where the peer receives messages
+	// output port is decided automatically randomly by operating system
+	Address string
+	Port    int
+	// Timeout is the maximum time Lock and TryLock wait for the permission of
+	// the peers before abandoning the request, zero means wait forever
+	Timeout time.Duration
+	// Members are the "address:port" of all the peers, this one included, in
+	// the same order on every peer. The quorum and tree based algorithms use
+	// it to compute the structure they need.
+	Members []string
+	// Parent is the "address:port" of the parent of this peer in the spanning
+	// tree of the tree based algorithms, empty for the root that starts with the token
+	Parent string
+	// Coordinator is the "address:port" of the lock server of the centralized algorithm
+	Coordinator string
+	// Election is the algorithm electing a new coordinator when it fails,
+	// Bully when empty. It uses Members to know the peers and their priority.
+	Election string
+	// Slots is the number of peers that can hold the lock at the same time,
+	// zero means one. Only Ricart & Agrawala supports more than one.
+	Slots int
+	// ResourceSlots is the number of slots of the named resources, Slots is
+	// used for the ones not listed
+	ResourceSlots map[string]int
+	// Heartbeat is the interval between the heartbeats of the failure detector,
+	// zero disables it. A peer is suspected after 3 intervals without news and
+	// its permission is not waited for anymore.
+	Heartbeat time.Duration
+	// StateLog is the path of the write-ahead log of the clock and of the
+	// state, replayed when the peer starts again. Empty disables it, only
+	// Ricart & Agrawala supports it.
+	StateLog string
+	// Gossip is the interval of the SWIM-style discovery of the members,
+	// zero disables it. The members discovered are kept sorted, the same on
+	// every peer. Not supported by the quorum and tree based algorithms.
+	Gossip time.Duration
+	// Seeds are the "address:port" of running members contacted at start by
+	// the discovery, a new peer needs only one. Listen joins the members
+	// through the first seed answering, and fails if none does.
+	Seeds []string
+	// TLS enables mutual TLS between the peers, all of them must use it
+	TLS *TLS
+	// Secret is the key shared by all the peers signing every message with an
+	// HMAC, empty disables the signatures
+	Secret []byte
+	// Token must be set on exactly one peer when a token based algorithm is
+	// used, that peer holds the token at start
+	Token bool
+}
+
+// node is the part shared by all the algorithms: the gRPC server, the
+// connections to the others peers and the Lamport clock.
+// Each algorithm embeds it and implements its own RPCs, the others answer Unimplemented.
+type node struct {
+	proto.UnimplementedMutualExlusionServiceServer
+	id      string
+	name    string
+	address string
+	port    int
+	timeout time.Duration
+	// membersMu protects members, that changes when the peers join and leave
+	membersMu sync.Mutex
+	members   []string
+	// removed by SetMembers, not connected again when they send a message
+	removed map[string]bool
+	// the members of the algorithms with a structure built from them can't change
+	fixed bool
+	// Lamport clock shared by the server and the client side
+	clock *LamportClock
+	// store tcp connection to others peers
+	peers    *registry
+	detector *detector
+	// discovery spreads the members when Gossip is set
+	discovery *gossip
+	server    *grpc.Server
+	// files of the mutual TLS, loaded by listen into credentials
+	tls         *TLS
+	credentials *peerCredentials
+	// key of the signatures and nonces already received
+	secret  []byte
+	replays *replays
+	// the algorithm served, to take the lock when the members change
+	locker Locker
+	// turn of every lock of this peer, by resource name
+	turnsMu sync.Mutex
+	turns   map[string]chan struct{}
+	// closed by Close to stop the heartbeats
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newNode(config Config) *node {
+	id := config.ID
+	if id == "" {
+		id = config.Address + ":" + strconv.Itoa(config.Port)
+	}
+	return &node{
+		id:        id,
+		name:      config.Name,
+		address:   config.Address,
+		port:      config.Port,
+		timeout:   config.Timeout,
+		members:   config.Members,
+		removed:   make(map[string]bool),
+		tls:       config.TLS,
+		secret:    config.Secret,
+		replays:   newReplays(),
+		clock:     &LamportClock{},
+		peers:     newRegistry(),
+		detector:  newDetector(config.Heartbeat),
+		discovery: newGossip(config.Gossip, config.Seeds),
+		done:      make(chan struct{}),
+		turns:     make(map[string]chan struct{}),
+	}
+}
+
+// listen opens the port to new connections and serves service in background.
+// It returns once the port is open, so it is safe to connect to the others peers after it.
+func (n *node) listen(service proto.MutualExlusionServiceServer) error {
+	if n.tls != nil {
+		credentials, err := loadCredentials(n.tls)
+		if err != nil {
+			return err
+		}
+		n.credentials = credentials
+	}
+	// Create a new grpc server
+	n.server = grpc.NewServer(n.serverOptions()...)
+	n.locker, _ = service.(Locker)
+
+	n.clock.Tick()
+	// Make the peer listen at the given port (convert int port to string)
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s", n.address, strconv.Itoa(n.port)))
+	if err != nil {
+		return err
+	}
+	log.Printf("Lamport %d: Started peer receiving at address: %s and at port: %d\n", n.clock.Now(), n.address, n.port)
+
+	// Register the grpc service
+	n.clock.Tick()
+	proto.RegisterMutualExlusionServiceServer(n.server, service)
+	go func() {
+		if err := n.server.Serve(listener); err != nil {
+			log.Printf("Lamport %d: Could not serve listener: %v", n.clock.Now(), err)
+		}
+	}()
+	log.Printf("Lamport %d: Started gRPC service", n.clock.Now())
+	if n.detector.interval > 0 {
+		go n.heartbeat(n.done)
+	}
+	if n.discovery.interval > 0 && !n.fixed {
+		if err := n.joinSeeds(); err != nil {
+			n.server.Stop()
+			return err
+		}
+		go n.gossip(n.done)
+	}
+	return nil
+}
+
+// Close stops answering the others peers and closes the connections to them
+func (n *node) Close() {
+	n.closeOnce.Do(func() { close(n.done) })
+	if n.server != nil {
+		n.server.Stop()
+	}
+	n.peers.close()
+}
+
+// Connect adds the peer at address:port to the peers taking part in the mutual exclusion
+func (n *node) Connect(address string, port int) {
+	peerRef := address + ":" + strconv.Itoa(port)
+	// Dial doesn't check if the peer at that address:host is effectivly on (simply prepare TCP connection)
+	n.clock.Tick()
+	conn, err := grpc.Dial(peerRef, n.dialOptions(peerRef)...)
+	if err != nil {
+		log.Printf("Lamport %d: Could not connect to peer %s at port %d", n.clock.Now(), address, port)
+		return
+	}
+	if n.peers.add(peerRef, conn) {
+		log.Printf("Lamport %d: Created TCP connection to the %s address at port %d\n", n.clock.Now(), address, port)
+	}
+}
+
+// Disconnect removes the peer at address:port and closes the connection to it
+func (n *node) Disconnect(address string, port int) {
+	n.peers.remove(address + ":" + strconv.Itoa(port))
+}
+
+// client returns the connection to peerRef, connecting to it if needed
+func (n *node) client(peerRef string) (proto.MutualExlusionServiceClient, error) {
+	if peer, found := n.peers.get(peerRef); found {
+		return peer, nil
+	}
+	address, port, err := net.SplitHostPort(peerRef)
+	if err != nil {
+		return nil, err
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, err
+	}
+	n.Connect(address, portNumber)
+	if peer, found := n.peers.get(peerRef); found {
+		return peer, nil
+	}
+	return nil, fmt.Errorf("mutex: could not connect to peer %s", peerRef)
+}
+
+// self is the "address:port" of this peer
+func (n *node) self() string {
+	return n.address + ":" + strconv.Itoa(n.port)
+}
+
+// Clock returns the Lamport clock of the peer
+func (n *node) Clock() *LamportClock {
+	return n.clock
+}
+
+// reference identifies this peer in the messages sent to the others
+func (n *node) reference() *proto.ClientReference {
+	return &proto.ClientReference{
+		ClientAddress: n.address,
+		ClientPort:    int32(n.port),
+		ClientName:    n.name,
+	}
+}
+
+// sender returns the "address:port" of the peer that sent a message and
+// connects to it if it is not known: it can be a reconnected peer or one not
+// present in the configuration file
+func (n *node) sender(ref *proto.ClientReference) string {
+	peerRef := ref.ClientAddress + ":" + strconv.Itoa(int(ref.ClientPort))
+	if !n.peers.has(peerRef) && !n.isRemoved(peerRef) {
+		n.Connect(ref.ClientAddress, int(ref.ClientPort))
+	}
+	return peerRef
+}
+
+// isRemoved reports if peerRef has been removed from the members by SetMembers
+func (n *node) isRemoved(peerRef string) bool {
+	n.membersMu.Lock()
+	defer n.membersMu.Unlock()
+	return n.removed[peerRef]
+}
+
+// withTimeout applies the configured timeout to ctx
+func (n *node) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if n.timeout > 0 {
+		return context.WithTimeout(ctx, n.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// abandoned returns the error reported to the caller when ctx ends before the lock is taken
+func abandoned(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ctx.Err()
+}
+
+// refused reports if err is a refusal of the message or of the connection by
+// the peer, not the peer gone away, so its permission can't be skipped
+func refused(err error) bool {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return true
+	case codes.Unavailable:
+		// a failed TLS handshake, on either side
+		message := status.Convert(err).Message()
+		return strings.Contains(message, "authentication handshake failed") || strings.Contains(message, "remote error: tls")
+	}
+	return false
+}
+
+// refusal returns the error reported to the caller when peerRef refused the request
+func refusal(peerRef string, err error) error {
+	return fmt.Errorf("%w: [%s] %v", ErrRefused, peerRef, status.Convert(err).Message())
+}
+
+// permission is the answer of a peer to a request of this peer
+type permission struct {
+	peerRef string
+	answer  *proto.Answer
+	err     error
+}
+
+// broadcast calls send for all the peers not suspected at the same time, the answers are
+// delivered on the returned channel as they arrive, so the slowest peer
+// decides the waiting time. The second value is the number of answers to expect.
+func (n *node) broadcast(ctx context.Context, send func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error)) (<-chan permission, int) {
+	return multicast(ctx, n.alive(), send)
+}
+
+// multicast is like broadcast but only for the given peers
+func multicast(ctx context.Context, peers map[string]proto.MutualExlusionServiceClient, send func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error)) (<-chan permission, int) {
+	permissions := make(chan permission, len(peers))
+	for index, peer := range peers {
+		go func(peerRef string, peer proto.MutualExlusionServiceClient) {
+			answer, err := send(ctx, peerRef, peer)
+			permissions <- permission{peerRef: peerRef, answer: answer, err: err}
+		}(index, peer)
+	}
+	return permissions, len(peers)
+}
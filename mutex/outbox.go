@@ -0,0 +1,42 @@
+package mutex
+
+import "sync"
+
+// outbox sends the messages of the algorithms that need FIFO channels.
+// The messages posted for a peer are delivered one after another in the order
+// they were posted, by a goroutine running only while there is something to
+// send, so posting never blocks.
+type outbox struct {
+	mu     sync.Mutex
+	queues map[string][]func()
+}
+
+// post queues send to be executed after the previous ones posted for peerRef
+func (o *outbox) post(peerRef string, send func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.queues == nil {
+		o.queues = make(map[string][]func())
+	}
+	queue, running := o.queues[peerRef]
+	o.queues[peerRef] = append(queue, send)
+	if !running {
+		go o.run(peerRef)
+	}
+}
+
+func (o *outbox) run(peerRef string) {
+	for {
+		o.mu.Lock()
+		queue := o.queues[peerRef]
+		if len(queue) == 0 {
+			delete(o.queues, peerRef)
+			o.mu.Unlock()
+			return
+		}
+		send := queue[0]
+		o.queues[peerRef] = queue[1:]
+		o.mu.Unlock()
+		send()
+	}
+}
@@ -0,0 +1,221 @@
+package mutex
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	proto "MutualExclusion/grpc"
+)
+
+// link for Raymond's algorithm https://www.geeksforgeeks.org/raymonds-tree-based-algorithm-for-mutual-exclusion/
+
+// RaymondMutex is a distributed mutex using Raymond's tree based algorithm:
+// the peers are arranged in a spanning tree and every peer points (HOLDER) to
+// the neighbour in the direction of the token. Requests travel along the
+// pointers and the token comes back on the same path reversing them, so with
+// a balanced tree every entry costs O(log N) messages.
+//
+// Every peer keeps a FIFO queue of the neighbours, or itself, that asked for
+// the token. The algorithm needs FIFO channels between neighbours, so the
+// messages go through an outbox.
+type RaymondMutex struct {
+	*node
+	out outbox
+	// mu protects all the fields below
+	mu sync.Mutex
+	// neighbour in the direction of the token, this peer itself when it holds the token
+	holder string
+	// this peer is in the critical section
+	using bool
+	// neighbours, or this peer itself, waiting for the token
+	queue []string
+	// a request has been sent to the holder and the token is not arrived yet
+	asked bool
+	// closed when the token can be used, nil when this peer is not waiting for it
+	waiting chan struct{}
+}
+
+// NewRaymond creates a RaymondMutex, call Listen and Connect before using it.
+// The root of the tree, the peer without config.Parent, starts with the token.
+func NewRaymond(config Config) *RaymondMutex {
+	m := &RaymondMutex{node: newNode(config)}
+	m.fixed = true
+	m.holder = config.Parent
+	if m.holder == "" {
+		m.holder = m.self()
+	}
+	return m
+}
+
+// Listen opens the port to new connections and serves the gRPC service in background.
+// It returns once the port is open, so it is safe to connect to the others peers after it.
+func (m *RaymondMutex) Listen() error {
+	return m.listen(m)
+}
+
+// Lock blocks until the token arrives to this peer.
+// If ctx is done or the timeout expires before, ctx.Err() or ErrTimeout is
+// returned and the token will be passed on when it arrives.
+func (m *RaymondMutex) Lock(ctx context.Context) error {
+	_, err := m.serialize(ctx, "", func() (bool, error) {
+		err := m.lock(ctx)
+		return err == nil, err
+	})
+	return err
+}
+
+func (m *RaymondMutex) lock(ctx context.Context) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	arrived := make(chan struct{})
+	m.waiting = arrived
+	m.queue = append(m.queue, m.self())
+	m.assignPrivilege()
+	m.makeRequest()
+	m.mu.Unlock()
+
+	select {
+	case <-arrived:
+		log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+		return nil
+	case <-ctx.Done():
+		log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.using {
+			// the token arrived at the same time
+			log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+			return nil
+		}
+		m.waiting = nil
+		for i, queued := range m.queue {
+			if queued == m.self() {
+				m.queue = append(m.queue[:i], m.queue[i+1:]...)
+				break
+			}
+		}
+		return abandoned(ctx)
+	}
+}
+
+// TryLock enters the critical section only if the token is at this peer and no neighbour is waiting for it.
+// It never sends messages.
+func (m *RaymondMutex) TryLock(ctx context.Context) (bool, error) {
+	return m.serialize(ctx, "", m.tryLock)
+}
+
+func (m *RaymondMutex) tryLock() (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.holder != m.self() || m.using || len(m.queue) > 0 {
+		log.Printf("Lamport %d: The token is used or requested by another peer", m.clock.Now())
+		return false, nil
+	}
+	m.using = true
+	log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+	return true, nil
+}
+
+// Unlock passes the token to the first waiting neighbour, if any
+func (m *RaymondMutex) Unlock() {
+	m.mustHold("")
+	defer m.exit("")
+	log.Printf("Lamport %d: Ending critical section", m.clock.Tick())
+	m.mu.Lock()
+	m.using = false
+	m.assignPrivilege()
+	m.makeRequest()
+	m.mu.Unlock()
+}
+
+// assignPrivilege gives the token to the first of the queue if this peer holds it and doesn't use it, m.mu must be held
+func (m *RaymondMutex) assignPrivilege() {
+	for m.holder == m.self() && !m.using && len(m.queue) > 0 {
+		next := m.queue[0]
+		m.queue = m.queue[1:]
+		m.asked = false
+		if next != m.self() {
+			m.holder = next
+			m.send(next, false)
+			return
+		}
+		if m.waiting != nil {
+			m.using = true
+			close(m.waiting)
+			m.waiting = nil
+		}
+	}
+}
+
+// makeRequest asks the holder for the token if somebody is waiting for it here, m.mu must be held
+func (m *RaymondMutex) makeRequest() {
+	if m.holder != m.self() && len(m.queue) > 0 && !m.asked {
+		m.asked = true
+		m.send(m.holder, true)
+	}
+}
+
+// send posts a request, or the token when request is false, for neighbour, m.mu must be held
+func (m *RaymondMutex) send(neighbour string, request bool) {
+	m.out.post(neighbour, func() {
+		question := &proto.Question{
+			ClientReference: m.reference(),
+			Time:            int32(m.clock.Tick()),
+			PeerId:          m.id,
+		}
+		peer, err := m.client(neighbour)
+		if err == nil {
+			ctx, cancel := m.withTimeout(context.Background())
+			defer cancel()
+			var answer *proto.Answer
+			if request {
+				log.Printf("Lamport %d: Asked neighbour [%s] for the token", question.Time, neighbour)
+				answer, err = peer.RaymondRequest(ctx, question)
+			} else {
+				log.Printf("Lamport %d: Sent the token to neighbour [%s]", question.Time, neighbour)
+				answer, err = peer.RaymondPrivilege(ctx, question)
+			}
+			if err == nil {
+				m.clock.Witness(int(answer.Time))
+				return
+			}
+		}
+		log.Printf("Lamport %d: Neighbour [%s] no more available: %v", m.clock.Now(), neighbour, err)
+	})
+}
+
+func (m *RaymondMutex) RaymondRequest(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Neighbour [%s] asked for the token", m.clock.Now(), peerRef)
+
+	m.mu.Lock()
+	m.queue = append(m.queue, peerRef)
+	m.assignPrivilege()
+	m.makeRequest()
+	m.mu.Unlock()
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
+
+func (m *RaymondMutex) RaymondPrivilege(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Received the token from neighbour [%s]", m.clock.Now(), peerRef)
+
+	m.mu.Lock()
+	m.holder = m.self()
+	m.asked = false
+	m.assignPrivilege()
+	m.makeRequest()
+	m.mu.Unlock()
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
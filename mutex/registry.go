@@ -0,0 +1,90 @@
+package mutex
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+
+	proto "MutualExclusion/grpc"
+)
+
+// peerConn is the tcp connection to another peer
+type peerConn struct {
+	client proto.MutualExlusionServiceClient
+	// kept to close the connection when the peer is removed
+	conn *grpc.ClientConn
+}
+
+// registry stores the connections to the others peers, indexed by "address:port".
+// It is safe for concurrent use by the gRPC handlers and the client side.
+type registry struct {
+	mu    sync.RWMutex
+	peers map[string]peerConn
+}
+
+func newRegistry() *registry {
+	return &registry{peers: make(map[string]peerConn)}
+}
+
+// add stores the connection to peerRef. If the peer is already present the new
+// connection is closed and false is returned.
+func (r *registry) add(peerRef string, conn *grpc.ClientConn) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, found := r.peers[peerRef]; found {
+		conn.Close()
+		return false
+	}
+	r.peers[peerRef] = peerConn{
+		client: proto.NewMutualExlusionServiceClient(conn),
+		conn:   conn,
+	}
+	return true
+}
+
+// remove deletes peerRef and closes its connection
+func (r *registry) remove(peerRef string) {
+	r.mu.Lock()
+	peer, found := r.peers[peerRef]
+	delete(r.peers, peerRef)
+	r.mu.Unlock()
+	if found {
+		peer.conn.Close()
+	}
+}
+
+func (r *registry) has(peerRef string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, found := r.peers[peerRef]
+	return found
+}
+
+func (r *registry) get(peerRef string) (proto.MutualExlusionServiceClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	peer, found := r.peers[peerRef]
+	return peer.client, found
+}
+
+// snapshot returns a copy of the current peers, safe to range over while peers are added or removed
+func (r *registry) snapshot() map[string]proto.MutualExlusionServiceClient {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	peers := make(map[string]proto.MutualExlusionServiceClient, len(r.peers))
+	for peerRef, peer := range r.peers {
+		peers[peerRef] = peer.client
+	}
+	return peers
+}
+
+// close removes all the peers and closes their connections
+func (r *registry) close() {
+	r.mu.Lock()
+	peers := r.peers
+	r.peers = make(map[string]peerConn)
+	r.mu.Unlock()
+	for _, peer := range peers {
+		peer.conn.Close()
+	}
+}
@@ -0,0 +1,66 @@
+package mutex
+
+import (
+	"context"
+	"log"
+
+	proto "MutualExclusion/grpc"
+)
+
+// Resource is one of the named locks of a Mutex, the requests for it go over
+// the same connections as the others resources but have their own state.
+// All the peers using the same name share the lock.
+type Resource struct {
+	m    *Mutex
+	name string
+}
+
+// Name returns the name of the resource
+func (r *Resource) Name() string {
+	return r.name
+}
+
+// Lock blocks until every peer gave its permission to use the resource, like Mutex.Lock
+func (r *Resource) Lock(ctx context.Context) error {
+	_, err := r.m.serialize(ctx, r.name, func() (bool, error) {
+		return r.m.acquire(ctx, r.name, false, proto.Question_EXCLUSIVE)
+	})
+	return err
+}
+
+// TryLock is like Lock but returns false, without waiting, when another peer
+// holds the resource, or is waiting for it with an earlier request
+// (lower time, or same time and lower id).
+func (r *Resource) TryLock(ctx context.Context) (bool, error) {
+	return r.m.serialize(ctx, r.name, func() (bool, error) {
+		return r.m.acquire(ctx, r.name, true, proto.Question_EXCLUSIVE)
+	})
+}
+
+// RLock is like Lock but in shared mode
+func (r *Resource) RLock(ctx context.Context) error {
+	_, err := r.m.serialize(ctx, r.name, func() (bool, error) {
+		return r.m.acquire(ctx, r.name, false, proto.Question_SHARED)
+	})
+	return err
+}
+
+// TryRLock is like TryLock but in shared mode
+func (r *Resource) TryRLock(ctx context.Context) (bool, error) {
+	return r.m.serialize(ctx, r.name, func() (bool, error) {
+		return r.m.acquire(ctx, r.name, true, proto.Question_SHARED)
+	})
+}
+
+// Unlock releases the resource, the requests deferred meanwhile are answered
+func (r *Resource) Unlock() {
+	r.m.mustHold(r.name)
+	defer r.m.exit(r.name)
+	log.Printf("Lamport %d: Ending critical section%s", r.m.clock.Tick(), on(r.name))
+	r.m.release(r.name)
+}
+
+// RUnlock releases the resource held in shared mode
+func (r *Resource) RUnlock() {
+	r.Unlock()
+}
@@ -0,0 +1,30 @@
+package mutex
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResourcesIndependent(t *testing.T) {
+	lockers := cluster(t, RicartAgrawala, 2)
+	a := lockers[0].(*Mutex).Resource("a")
+	if err := a.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	b := lockers[1].(*Mutex).Resource("b")
+	if err := b.Lock(ctx); err != nil {
+		t.Fatalf("Lock of b while a is held: %v", err)
+	}
+	ok, err := lockers[1].(*Mutex).Resource("a").TryLock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("TryLock took a while another peer holds it")
+	}
+	b.Unlock()
+	a.Unlock()
+}
@@ -0,0 +1,31 @@
+package mutex
+
+import "context"
+
+// Semaphore is a distributed counting semaphore: up to k peers can hold it at
+// the same time, for example to share a pool of k licenses. It is a Mutex
+// with k slots, all the peers must use the same k.
+type Semaphore struct {
+	*Mutex
+}
+
+// NewSemaphore creates a Semaphore with k slots, call Listen and Connect before using it
+func NewSemaphore(config Config, k int) *Semaphore {
+	config.Slots = k
+	return &Semaphore{Mutex: New(config)}
+}
+
+// Acquire blocks until this peer takes one of the k slots, it is the same as Lock
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	return s.Lock(ctx)
+}
+
+// TryAcquire takes a slot only if one is free, it is the same as TryLock
+func (s *Semaphore) TryAcquire(ctx context.Context) (bool, error) {
+	return s.TryLock(ctx)
+}
+
+// Release gives the slot back, it is the same as Unlock
+func (s *Semaphore) Release() {
+	s.Unlock()
+}
@@ -0,0 +1,80 @@
+package mutex
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	lockers := clusterWith(t, RicartAgrawala, 3, func(config *Config) { config.Slots = 2 })
+	semaphores := make([]*Semaphore, len(lockers))
+	for i, m := range lockers {
+		semaphores[i] = &Semaphore{Mutex: m.(*Mutex)}
+	}
+	for _, s := range semaphores[:2] {
+		if err := s.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ok, err := semaphores[2].TryAcquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("TryAcquire took a third slot of 2")
+	}
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- semaphores[2].Acquire(context.Background()) }()
+	select {
+	case err := <-acquired:
+		t.Fatalf("third peer acquired a semaphore of 2 slots: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	semaphores[0].Release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("third peer still waiting after a slot was released")
+	}
+	semaphores[1].Release()
+	semaphores[2].Release()
+}
+
+func TestResourceSlots(t *testing.T) {
+	lockers := clusterWith(t, RicartAgrawala, 3, func(config *Config) {
+		config.ResourceSlots = map[string]int{"pool": 2}
+	})
+	for _, m := range lockers[:2] {
+		if err := m.(*Mutex).Resource("pool").Lock(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ok, err := lockers[2].(*Mutex).Resource("pool").TryLock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("TryLock took a third slot of the resource with 2")
+	}
+
+	// the others resources keep one slot
+	if err := lockers[0].Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ok, err = lockers[1].TryLock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("TryLock took a second slot of the default resource")
+	}
+	lockers[0].Unlock()
+	for _, m := range lockers[:2] {
+		m.(*Mutex).Resource("pool").Unlock()
+	}
+}
@@ -0,0 +1,407 @@
+package mutex
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// link for Suzuki–Kasami algorithm https://www.geeksforgeeks.org/suzuki-kasami-algorithm-for-mutual-exclusion-in-distributed-system/
+
+// skToken is the token of the Suzuki–Kasami algorithm
+type skToken struct {
+	// number of the last served request of every peer id (LN array)
+	lastServed map[string]int
+	// ids of the peers waiting for the token
+	queue []string
+	// number of times the token was sent
+	generation int
+}
+
+// tokenRetry is the pause before sending again a token that could have been lost
+const tokenRetry = time.Second
+
+// errNotConnected is returned when the token is sent to a peer not connected
+var errNotConnected = errors.New("mutex: peer not connected")
+
+// SuzukiKasamiMutex is a distributed mutex using the Suzuki–Kasami algorithm:
+// a single token circulates between the peers and only the peer holding it
+// can enter the critical section. A peer without the token broadcasts a
+// numbered request, the holder sends the token to the waiting peers in order
+// when it leaves the critical section.
+//
+// A request abandoned because of a timeout is already known to every peer, so
+// when the token arrives afterwards it is just passed to the next waiting peer.
+//
+// The token is taken back only when it surely did not reach the next peer.
+// When it could have arrived, for example the answer timed out, the same
+// generation of the token is sent again until the peer acknowledges it: a
+// peer drops a copy not newer than the last token it received, so a token is
+// never duplicated.
+type SuzukiKasamiMutex struct {
+	*node
+	// mu protects all the fields below
+	mu sync.Mutex
+	// highest request number received from every peer id (RN array)
+	requested map[string]int
+	// "address:port" of every peer id, to send it the token
+	refs map[string]string
+	// nil when the token is at another peer
+	token *skToken
+	// generation of the last token received
+	generation int
+	// this peer holds the token to execute the critical section
+	inside bool
+	// closed when the token arrives, nil when this peer is not waiting for it
+	waiting chan struct{}
+}
+
+// NewSuzukiKasami creates a SuzukiKasamiMutex, call Listen and Connect before using it.
+// The peer with config.Token set starts with the token.
+func NewSuzukiKasami(config Config) *SuzukiKasamiMutex {
+	m := &SuzukiKasamiMutex{
+		node:      newNode(config),
+		requested: make(map[string]int),
+		refs:      make(map[string]string),
+	}
+	if config.Token {
+		m.token = &skToken{lastServed: make(map[string]int)}
+	}
+	return m
+}
+
+// Listen opens the port to new connections and serves the gRPC service in background.
+// It returns once the port is open, so it is safe to connect to the others peers after it.
+func (m *SuzukiKasamiMutex) Listen() error {
+	return m.listen(m)
+}
+
+// Lock blocks until this peer has the token.
+// If ctx is done or the timeout expires before, ctx.Err() or ErrTimeout is
+// returned and the token will be passed on when it arrives.
+func (m *SuzukiKasamiMutex) Lock(ctx context.Context) error {
+	_, err := m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, false) })
+	return err
+}
+
+// TryLock is like Lock but returns false, without waiting, when the token is
+// used or it is not sent immediately by its holder.
+func (m *SuzukiKasamiMutex) TryLock(ctx context.Context) (bool, error) {
+	return m.serialize(ctx, "", func() (bool, error) { return m.acquire(ctx, true) })
+}
+
+// Unlock gives the token to the next waiting peer, if any
+func (m *SuzukiKasamiMutex) Unlock() {
+	m.mustHold("")
+	defer m.exit("")
+	log.Printf("Lamport %d: Ending critical section", m.clock.Tick())
+	m.mu.Lock()
+	m.inside = false
+	if m.token != nil {
+		m.token.lastServed[m.id] = m.requested[m.id]
+	}
+	m.mu.Unlock()
+	m.forward()
+}
+
+func (m *SuzukiKasamiMutex) acquire(ctx context.Context, try bool) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	if m.token != nil {
+		// no need to ask, the token is here
+		m.inside = true
+		m.mu.Unlock()
+		log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+		return true, nil
+	}
+	m.requested[m.id]++
+	sequence := m.requested[m.id]
+	arrived := make(chan struct{})
+	m.waiting = arrived
+	m.mu.Unlock()
+
+	question := &proto.Question{
+		ClientReference: m.reference(),
+		Time:            int32(m.clock.Tick()),
+		PeerId:          m.id,
+		TryLock:         try,
+		Sequence:        int32(sequence),
+	}
+	answers, count := m.broadcast(ctx, func(ctx context.Context, peerRef string, peer proto.MutualExlusionServiceClient) (*proto.Answer, error) {
+		log.Printf("Lamport %d: Asked Peer [%s] for the token", m.clock.Tick(), peerRef)
+		return peer.SuzukiKasamiRequest(ctx, question)
+	})
+	coming := false
+	for i := 0; i < count; i++ {
+		var p permission
+		select {
+		case p = <-answers:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if refused(p.err) {
+			log.Printf("Lamport %d: Peer [%s] refused the request: %v", m.clock.Now(), p.peerRef, p.err)
+			if m.abandon() {
+				return true, nil
+			}
+			return false, refusal(p.peerRef, p.err)
+		}
+		if p.err != nil {
+			log.Printf("Lamport %d: Peer [%s] no more available, removed from connected peers", m.clock.Now(), p.peerRef)
+			m.peers.remove(p.peerRef)
+			continue
+		}
+		m.clock.Witness(int(p.answer.Time))
+		coming = coming || p.answer.Reply
+	}
+
+	if try && !coming {
+		log.Printf("Lamport %d: The token is used by another peer", m.clock.Now())
+		return m.abandon(), nil
+	}
+	select {
+	case <-arrived:
+		log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+		return true, nil
+	case <-ctx.Done():
+		log.Printf("Lamport %d: Request abandoned: %v", m.clock.Now(), ctx.Err())
+		if m.abandon() {
+			// the token arrived at the same time
+			return true, nil
+		}
+		return false, abandoned(ctx)
+	}
+}
+
+// abandon stops waiting for the token, it returns true if the token arrived in the meantime
+func (m *SuzukiKasamiMutex) abandon() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waiting = nil
+	if m.inside {
+		log.Printf("Lamport %d: Starting critical section", m.clock.Tick())
+		return true
+	}
+	return false
+}
+
+// forward sends the token to the first waiting peer if this peer holds it and doesn't use it
+func (m *SuzukiKasamiMutex) forward() {
+	for {
+		m.mu.Lock()
+		if m.token == nil || m.inside {
+			m.mu.Unlock()
+			return
+		}
+		// add to the queue the peers with a request not served yet, the
+		// request number can be ahead by more than one after an abandoned request
+		ids := make([]string, 0, len(m.requested))
+		for id := range m.requested {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if id != m.id && m.requested[id] > m.token.lastServed[id] && !contains(m.token.queue, id) {
+				m.token.queue = append(m.token.queue, id)
+			}
+		}
+		if len(m.token.queue) == 0 {
+			m.mu.Unlock()
+			return
+		}
+		next := m.token.queue[0]
+		token := m.token
+		token.queue = token.queue[1:]
+		token.generation++
+		m.token = nil
+		peerRef := m.refs[next]
+		m.mu.Unlock()
+
+		err := m.sendToken(peerRef, token)
+		if err == nil {
+			return
+		}
+		if !notDelivered(err) {
+			go m.resend(peerRef, token, err)
+			return
+		}
+		// take the token back and skip the peer
+		log.Printf("Lamport %d: Peer [%s] no more available, removed from connected peers", m.clock.Now(), peerRef)
+		m.peers.remove(peerRef)
+		m.mu.Lock()
+		token.lastServed[next] = m.requested[next]
+		m.token = token
+		m.mu.Unlock()
+	}
+}
+
+// sendToken sends token to peerRef, an error doesn't mean it didn't arrive, see notDelivered
+func (m *SuzukiKasamiMutex) sendToken(peerRef string, token *skToken) error {
+	peer, found := m.peers.get(peerRef)
+	if !found {
+		return errNotConnected
+	}
+	lastServed := make(map[string]int32, len(token.lastServed))
+	for id, sequence := range token.lastServed {
+		lastServed[id] = int32(sequence)
+	}
+	ctx, cancel := m.withTimeout(context.Background())
+	defer cancel()
+	log.Printf("Lamport %d: Sent the token to peer [%s]", m.clock.Tick(), peerRef)
+	answer, err := peer.SuzukiKasamiToken(ctx, &proto.Token{
+		ClientReference: m.reference(),
+		Time:            int32(m.clock.Now()),
+		PeerId:          m.id,
+		LastServed:      lastServed,
+		Queue:           token.queue,
+		Generation:      int32(token.generation),
+	})
+	if err != nil {
+		return err
+	}
+	m.clock.Witness(int(answer.Time))
+	return nil
+}
+
+// notDelivered reports if the token surely did not reach the peer: it was not
+// connected or the connection failed before sending it
+func notDelivered(err error) bool {
+	return errors.Is(err, errNotConnected) || status.Code(err) == codes.Unavailable && strings.HasPrefix(status.Convert(err).Message(), "connection error")
+}
+
+// resend sends again the token that could have been lost to peerRef, until
+// it acknowledges it. The token is not given to another peer since it could
+// be already at peerRef, it is lost if peerRef left or this peer is closed.
+func (m *SuzukiKasamiMutex) resend(peerRef string, token *skToken, err error) {
+	for err != nil {
+		log.Printf("Lamport %d: The token could have reached peer [%s], sending it again: %v", m.clock.Now(), peerRef, err)
+		select {
+		case <-time.After(tokenRetry):
+		case <-m.done:
+			return
+		}
+		if !contains(m.memberList(), peerRef) {
+			log.Printf("Lamport %d: Peer [%s] left, the token sent to it is lost", m.clock.Now(), peerRef)
+			return
+		}
+		err = m.sendToken(peerRef, token)
+	}
+}
+
+// leave gives the token to another peer before this one leaves the network
+func (m *SuzukiKasamiMutex) leave() {
+	m.mu.Lock()
+	token := m.token
+	if token == nil {
+		m.mu.Unlock()
+		return
+	}
+	token.lastServed[m.id] = m.requested[m.id]
+	token.generation++
+	m.token = nil
+	m.mu.Unlock()
+	for peerRef := range m.alive() {
+		err := m.sendToken(peerRef, token)
+		if err == nil {
+			return
+		}
+		if !notDelivered(err) {
+			m.resend(peerRef, token, err)
+			return
+		}
+	}
+	// nobody to give it to
+	m.mu.Lock()
+	m.token = token
+	m.mu.Unlock()
+}
+
+func (m *SuzukiKasamiMutex) SuzukiKasamiRequest(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Peer [%s] asked for the token with request %d", m.clock.Now(), peerRef, in.Sequence)
+
+	m.mu.Lock()
+	m.refs[in.PeerId] = peerRef
+	if int(in.Sequence) > m.requested[in.PeerId] {
+		m.requested[in.PeerId] = int(in.Sequence)
+	}
+	// the token is sent if it is here and not used
+	sending := m.token != nil && !m.inside
+	m.mu.Unlock()
+	if sending {
+		go m.forward()
+	}
+	return &proto.Answer{
+		Reply: sending,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
+
+func (m *SuzukiKasamiMutex) SuzukiKasamiToken(ctx context.Context, in *proto.Token) (*proto.Answer, error) {
+	m.clock.Witness(int(in.Time))
+	peerRef := m.sender(in.ClientReference)
+	log.Printf("Lamport %d: Received the token from peer [%s]", m.clock.Now(), peerRef)
+
+	token := &skToken{
+		lastServed: make(map[string]int, len(in.LastServed)),
+		queue:      in.Queue,
+		generation: int(in.Generation),
+	}
+	for id, sequence := range in.LastServed {
+		token.lastServed[id] = int(sequence)
+	}
+	m.mu.Lock()
+	m.refs[in.PeerId] = peerRef
+	if token.generation <= m.generation {
+		m.mu.Unlock()
+		log.Printf("Lamport %d: Token of generation %d already received, dropped", m.clock.Now(), token.generation)
+		return &proto.Answer{
+			Reply: true,
+			Time:  int32(m.clock.Tick()),
+		}, nil
+	}
+	m.generation = token.generation
+	m.token = token
+	waiting := m.waiting
+	if waiting != nil {
+		m.inside = true
+		m.waiting = nil
+	}
+	m.mu.Unlock()
+	if waiting != nil {
+		close(waiting)
+	} else {
+		// nobody waits for it anymore
+		m.mu.Lock()
+		token.lastServed[m.id] = m.requested[m.id]
+		m.mu.Unlock()
+		go m.forward()
+	}
+	return &proto.Answer{
+		Reply: true,
+		Time:  int32(m.clock.Tick()),
+	}, nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
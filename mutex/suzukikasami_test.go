@@ -0,0 +1,43 @@
+package mutex
+
+import (
+	"context"
+	"testing"
+
+	proto "MutualExclusion/grpc"
+)
+
+func TestSuzukiKasamiTokenRetransmission(t *testing.T) {
+	ports := freePorts(t, 2)
+	m := NewSuzukiKasami(Config{Address: "127.0.0.1", Port: ports[0]})
+	defer m.Close()
+	token := func(generation int) *proto.Token {
+		return &proto.Token{
+			ClientReference: &proto.ClientReference{ClientAddress: "127.0.0.1", ClientPort: int32(ports[1])},
+			PeerId:          "other",
+			LastServed:      map[string]int32{},
+			Generation:      int32(generation),
+		}
+	}
+
+	if _, err := m.SuzukiKasamiToken(context.Background(), token(2)); err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	if m.token == nil {
+		t.Fatal("token of generation 2 not taken")
+	}
+	// passed on to another peer
+	m.token = nil
+	m.mu.Unlock()
+
+	// the answer of the first copy was lost and the sender sent it again
+	if _, err := m.SuzukiKasamiToken(context.Background(), token(2)); err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token != nil {
+		t.Error("the retransmitted token of generation 2 was taken again")
+	}
+}
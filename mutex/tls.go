@@ -0,0 +1,169 @@
+package mutex
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// TLS holds the PEM files of the mutual TLS between the peers
+type TLS struct {
+	// certificate and key of this peer, the common name of the certificate
+	// must be the "address:port" of the peer
+	CertFile string
+	KeyFile  string
+	// certificate of the authority signing the certificates of all the peers
+	CAFile string
+}
+
+// With mutual TLS both sides of a connection show a certificate signed by the
+// authority. The identity of a peer is the common name of its certificate: a
+// client checks it is the peer it dialed, a server checks it is the peer the
+// message claims to come from in its ClientReference.
+
+// peerCredentials holds the certificate of this peer and the authority, loaded once
+type peerCredentials struct {
+	cert tls.Certificate
+	ca   *x509.CertPool
+}
+
+func loadCredentials(files *TLS) (*peerCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("mutex: could not load the certificate: %w", err)
+	}
+	pem, err := os.ReadFile(files.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("mutex: could not load the authority: %w", err)
+	}
+	ca := x509.NewCertPool()
+	if !ca.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("mutex: no certificate in the authority file %s", files.CAFile)
+	}
+	return &peerCredentials{cert: cert, ca: ca}, nil
+}
+
+// verify checks the certificates shown by the other side are signed by the
+// authority, the usage is not checked since a peer is both client and server
+func (c *peerCredentials) verify(certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return errors.New("mutex: no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         c.ca,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// server returns the TLS configuration of the gRPC server
+func (c *peerCredentials) server() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{c.cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+		MinVersion:   tls.VersionTLS12,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			return c.verify(state.PeerCertificates)
+		},
+	}
+}
+
+// client returns the TLS configuration of the connection to peerRef
+func (c *peerCredentials) client(peerRef string) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{c.cert},
+		// the chain and the identity are checked by VerifyConnection,
+		// the host name can't tell apart two peers with the same address
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS12,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if err := c.verify(state.PeerCertificates); err != nil {
+				return err
+			}
+			if identity := state.PeerCertificates[0].Subject.CommonName; identity != peerRef {
+				return fmt.Errorf("mutex: dialed peer %s but the certificate is of %s", peerRef, identity)
+			}
+			return nil
+		},
+	}
+}
+
+// serverOptions returns the options of the gRPC server, with mutual TLS and
+// the check of the signatures if configured
+func (n *node) serverOptions() []grpc.ServerOption {
+	options := []grpc.ServerOption{}
+	interceptors := []grpc.UnaryServerInterceptor{}
+	if n.credentials != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(n.credentials.server())))
+		interceptors = append(interceptors, n.authenticate)
+	}
+	if len(n.secret) > 0 {
+		interceptors = append(interceptors, n.verify)
+	}
+	return append(options, grpc.ChainUnaryInterceptor(interceptors...))
+}
+
+// dialOptions returns the options of the connection to peerRef
+func (n *node) dialOptions(peerRef string) []grpc.DialOption {
+	options := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if n.credentials != nil {
+		options[0] = grpc.WithTransportCredentials(credentials.NewTLS(n.credentials.client(peerRef)))
+	}
+	if len(n.secret) > 0 {
+		options = append(options, grpc.WithUnaryInterceptor(n.sign))
+	}
+	return options
+}
+
+// authenticate refuses the messages whose ClientReference is not the peer of the certificate
+func (n *node) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	identity := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			identity = tlsInfo.State.PeerCertificates[0].Subject.CommonName
+		}
+	}
+	if identity == "" {
+		return nil, status.Error(codes.Unauthenticated, "mutex: no certificate")
+	}
+	claimed := ""
+	if message, ok := req.(interface{ GetClientReference() *proto.ClientReference }); ok && message.GetClientReference() != nil {
+		ref := message.GetClientReference()
+		claimed = ref.ClientAddress + ":" + strconv.Itoa(int(ref.ClientPort))
+	}
+	// a peer joins and leaves only for itself, only the member that took the
+	// lock for a join tells the others about another peer
+	if membership, ok := req.(*proto.Membership); ok {
+		if membership.Forwarded && info.FullMethod != addMemberMethod {
+			log.Printf("Lamport %d: Refused %s from [%s], only a join is forwarded", n.clock.Now(), info.FullMethod, identity)
+			return nil, errNotForwarded
+		}
+		if !membership.Forwarded && membership.Member != identity {
+			claimed = membership.Member
+		}
+	}
+	if claimed != identity {
+		log.Printf("Lamport %d: Refused %s from [%s] claiming to be [%s]", n.clock.Now(), info.FullMethod, identity, claimed)
+		return nil, status.Errorf(codes.PermissionDenied, "mutex: the certificate is of %s, not of %s", identity, claimed)
+	}
+	return handler(ctx, req)
+}
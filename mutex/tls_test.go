@@ -0,0 +1,169 @@
+package mutex
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	proto "MutualExclusion/grpc"
+)
+
+// authority writes in dir a certificate authority and, signed by it, a
+// certificate and a key for every common name. It returns the TLS files of
+// every common name.
+func authority(t *testing.T, dir string, names ...string) map[string]*TLS {
+	t.Helper()
+	write := func(name, kind string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mutex test authority"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := write("ca.pem", "CERTIFICATE", caDER)
+
+	files := make(map[string]*TLS, len(names))
+	for i, name := range names {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 2)),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = &TLS{
+			CertFile: write("cert"+strconv.Itoa(i)+".pem", "CERTIFICATE", der),
+			KeyFile:  write("key"+strconv.Itoa(i)+".pem", "EC PRIVATE KEY", keyDER),
+			CAFile:   caFile,
+		}
+	}
+	return files
+}
+
+func TestTLSWrongPeerRefused(t *testing.T) {
+	ports := freePorts(t, 3)
+	a := "127.0.0.1:" + strconv.Itoa(ports[0])
+	b := "127.0.0.1:" + strconv.Itoa(ports[1])
+	other := "127.0.0.1:" + strconv.Itoa(ports[2])
+	files := authority(t, t.TempDir(), b, other)
+
+	// the peer listening on the port of a shows the certificate of another peer
+	impostor := New(Config{Address: "127.0.0.1", Port: ports[0], TLS: files[other]})
+	if err := impostor.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer impostor.Close()
+	m := New(Config{Address: "127.0.0.1", Port: ports[1], TLS: files[b], Timeout: 5 * time.Second})
+	if err := m.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.Connect("127.0.0.1", ports[0])
+
+	if err := m.Lock(context.Background()); !errors.Is(err, ErrRefused) {
+		if err == nil {
+			m.Unlock()
+		}
+		t.Errorf("Lock with the certificate of %s at %s: got %v, want ErrRefused", other, a, err)
+	}
+}
+
+func TestTLSClientReferenceChecked(t *testing.T) {
+	ports := freePorts(t, 3)
+	a := "127.0.0.1:" + strconv.Itoa(ports[0])
+	b := "127.0.0.1:" + strconv.Itoa(ports[1])
+	files := authority(t, t.TempDir(), a, b)
+	m := New(Config{Address: "127.0.0.1", Port: ports[0], TLS: files[a]})
+	if err := m.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	// b connects with its own certificate but claims to be another peer
+	creds := credentialsOf(t, files[b], a)
+	conn, err := grpc.Dial(a, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := proto.NewMutualExlusionServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	claimed := &proto.ClientReference{ClientAddress: "127.0.0.1", ClientPort: int32(ports[2])}
+	_, err = client.AskPermission(ctx, &proto.Question{ClientReference: claimed, Time: 1, PeerId: "other"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("question with the ClientReference of another peer: got %v, want PermissionDenied", err)
+	}
+
+	// nobody removes another peer, even telling it is forwarded
+	self := &proto.ClientReference{ClientAddress: "127.0.0.1", ClientPort: int32(ports[1])}
+	_, err = client.RemoveMember(ctx, &proto.Membership{ClientReference: self, Time: 1, Member: a, Forwarded: true})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("forwarded departure of another peer: got %v, want PermissionDenied", err)
+	}
+	_, err = client.AskPermission(ctx, &proto.Question{ClientReference: self, Time: 2, PeerId: "b"})
+	if err != nil {
+		t.Errorf("question with the ClientReference of the certificate: %v", err)
+	}
+}
+
+// credentialsOf returns the transport credentials of the peer with files dialing peerRef
+func credentialsOf(t *testing.T, files *TLS, peerRef string) credentials.TransportCredentials {
+	t.Helper()
+	c, err := loadCredentials(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return credentials.NewTLS(c.client(peerRef))
+}
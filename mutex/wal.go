@@ -0,0 +1,270 @@
+package mutex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+)
+
+// operations recorded in the state log
+const (
+	// this peer requested a resource
+	walRequest = "request"
+	// this peer entered the critical section
+	walHeld = "held"
+	// this peer left the critical section or abandoned the request
+	walRelease = "release"
+	// the answer to a peer has been deferred
+	walDefer = "defer"
+	// a peer has been answered, or the deferred answer forgotten
+	walAnswer = "answer"
+)
+
+// walCompaction is the number of records after which the log is rewritten
+// with only the current state
+const walCompaction = 1000
+
+// walRecord is a line of the state log
+type walRecord struct {
+	// Lamport time when the record was written
+	Clock    int    `json:"clock"`
+	Op       string `json:"op"`
+	Resource string `json:"resource,omitempty"`
+	// peer deferred or answered
+	Peer string `json:"peer,omitempty"`
+	// time and mode of the request of this peer, or of the deferred peer
+	Time int `json:"time,omitempty"`
+	Mode int `json:"mode,omitempty"`
+}
+
+// walState is the clock and the state of the resources described by a log
+type walState struct {
+	clock     int
+	resources map[string]*walResource
+}
+
+// walResource is the state of a resource in the log
+type walResource struct {
+	state       int
+	requestTime int
+	mode        int
+	// defer records of the requests not answered yet
+	deferred []walRecord
+}
+
+func newWalState() *walState {
+	return &walState{resources: make(map[string]*walResource)}
+}
+
+// apply changes the state with record
+func (s *walState) apply(record walRecord) error {
+	if record.Clock > s.clock {
+		s.clock = record.Clock
+	}
+	r, found := s.resources[record.Resource]
+	if !found {
+		r = &walResource{state: Released}
+		s.resources[record.Resource] = r
+	}
+	switch record.Op {
+	case walRequest:
+		r.state = Wanted
+		r.requestTime = record.Time
+		r.mode = record.Mode
+	case walHeld:
+		r.state = Held
+	case walRelease:
+		r.state = Released
+	case walDefer:
+		r.deferred = append(r.deferred, record)
+	case walAnswer:
+		for i, deferred := range r.deferred {
+			if deferred.Peer == record.Peer {
+				r.deferred = append(r.deferred[:i], r.deferred[i+1:]...)
+				break
+			}
+		}
+	default:
+		return fmt.Errorf("unknown operation %q", record.Op)
+	}
+	if r.state == Released && len(r.deferred) == 0 {
+		// nothing to remember
+		delete(s.resources, record.Resource)
+	}
+	return nil
+}
+
+// snapshot returns the records giving the same state, the clock first
+func (s *walState) snapshot() []walRecord {
+	records := []walRecord{{Clock: s.clock, Op: walRelease}}
+	names := make([]string, 0, len(s.resources))
+	for name := range s.resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := s.resources[name]
+		if r.state != Released {
+			records = append(records, walRecord{Clock: s.clock, Op: walRequest, Resource: name, Time: r.requestTime, Mode: r.mode})
+		}
+		if r.state == Held {
+			records = append(records, walRecord{Clock: s.clock, Op: walHeld, Resource: name})
+		}
+		records = append(records, r.deferred...)
+	}
+	return records
+}
+
+// stateLog is a write-ahead log of the Lamport clock and of the state of a
+// Mutex: every record is on disk before the message it is about is sent, so
+// a restarted peer never sends a time lower than before the crash, and knows
+// the requests in progress and the answers it deferred.
+// The state is also kept in memory, to rewrite the log with only the current
+// state every walCompaction records. A nil stateLog records nothing.
+type stateLog struct {
+	mu    sync.Mutex
+	path  string
+	file  *os.File
+	state *walState
+	// records written since the last compaction
+	written int
+}
+
+// openStateLog replays the log at path, if any, and starts a new one with
+// the state replayed, which is returned
+func openStateLog(path string) (*stateLog, *walState, error) {
+	state, err := replay(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	l := &stateLog{path: path, state: state}
+	if err := l.compact(); err != nil {
+		return nil, nil, err
+	}
+	recovered := newWalState()
+	for _, record := range state.snapshot() {
+		recovered.apply(record)
+	}
+	return l, recovered, nil
+}
+
+// replay reads the log at path, a missing file is an empty log.
+// A last line cut by the crash is ignored, any other bad line is an error.
+func replay(path string) (*walState, error) {
+	state := newWalState()
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// error of the line read before, fine only if it is the last one
+	var cut error
+	for line := 1; scanner.Scan(); line++ {
+		if cut != nil {
+			return nil, fmt.Errorf("mutex: state log %s: %w", path, cut)
+		}
+		var record walRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			cut = fmt.Errorf("line %d: %w", line, err)
+			continue
+		}
+		if err := state.apply(record); err != nil {
+			return nil, fmt.Errorf("mutex: state log %s: line %d: %w", path, line, err)
+		}
+	}
+	if cut != nil {
+		log.Printf("State log %s: last line cut by the crash ignored: %v", path, cut)
+	}
+	return state, scanner.Err()
+}
+
+// compact replaces the log with the snapshot of the state, l.mu must be held
+// when the log is in use. The old log is replaced only once the new one is on disk.
+func (l *stateLog) compact() error {
+	tmp := l.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, record := range l.state.snapshot() {
+		line, err := json.Marshal(record)
+		if err == nil {
+			_, err = file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		file.Close()
+		return err
+	}
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = file
+	l.written = 0
+	return nil
+}
+
+// write appends record to the log and waits until it is on disk.
+// It is slow, don't call it holding a lock the others messages need.
+func (l *stateLog) write(record walRecord) error {
+	if l == nil {
+		return nil
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.state.apply(record); err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.written++
+	if l.written >= walCompaction {
+		if err := l.compact(); err != nil {
+			// the old log is still complete
+			log.Printf("Could not compact the state log: %v", err)
+		}
+	}
+	return nil
+}
+
+// record is write for the callers that can't stop on a failure, which is only logged
+func (l *stateLog) record(record walRecord) {
+	if err := l.write(record); err != nil {
+		log.Printf("Could not write the state log: %v", err)
+	}
+}
+
+func (l *stateLog) close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file.Close()
+}
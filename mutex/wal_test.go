@@ -0,0 +1,102 @@
+package mutex
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateLogRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.log")
+	wal, _, err := openStateLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal.record(walRecord{Clock: 3, Op: walRequest, Resource: "db", Time: 3})
+	wal.record(walRecord{Clock: 4, Op: walDefer, Resource: "db", Peer: "a", Time: 4})
+	wal.record(walRecord{Clock: 5, Op: walDefer, Resource: "db", Peer: "b", Time: 5})
+	wal.record(walRecord{Clock: 6, Op: walAnswer, Resource: "db", Peer: "a"})
+	wal.record(walRecord{Clock: 7, Op: walHeld, Resource: "db"})
+	wal.record(walRecord{Clock: 8, Op: walRequest, Resource: "cache", Time: 8})
+	wal.record(walRecord{Clock: 9, Op: walRelease, Resource: "cache"})
+	wal.close()
+
+	wal, state, err := openStateLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.close()
+	if state.clock != 9 {
+		t.Errorf("clock %d, want 9", state.clock)
+	}
+	if _, found := state.resources["cache"]; found {
+		t.Error("released resource still in the state")
+	}
+	db := state.resources["db"]
+	if db == nil || db.state != Held || db.requestTime != 3 {
+		t.Fatalf("db: got %+v, want held since 3", db)
+	}
+	if len(db.deferred) != 1 || db.deferred[0].Peer != "b" || db.deferred[0].Time != 5 {
+		t.Errorf("db: deferred %+v, want the request of b at 5", db.deferred)
+	}
+}
+
+func TestStateLogCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.log")
+	wal, _, err := openStateLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.close()
+	for i := 1; i <= walCompaction; i++ {
+		wal.record(walRecord{Clock: 2 * i, Op: walRequest, Resource: "db", Time: 2 * i})
+		wal.record(walRecord{Clock: 2*i + 1, Op: walRelease, Resource: "db"})
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		lines++
+	}
+	if lines > walCompaction {
+		t.Errorf("%d lines in the log, it was not compacted", lines)
+	}
+	state, err := replay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.clock != 2*walCompaction+1 {
+		t.Errorf("clock %d after the compaction, want %d", state.clock, 2*walCompaction+1)
+	}
+}
+
+func TestStateLogCorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.log")
+	cut := `{"clock":3,"op":"request","time":3}
+{"clock":4,"op":"defer","peer":"a","ti`
+	if err := os.WriteFile(path, []byte(cut), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state, err := replay(path)
+	if err != nil {
+		t.Fatalf("last line cut by a crash: %v", err)
+	}
+	if state.clock != 3 {
+		t.Errorf("clock %d, want 3", state.clock)
+	}
+
+	corrupt := `{"clock":3,"op":"request","time":3}
+{"clock":4,"op":"defer","peer":"a","ti
+{"clock":5,"op":"held"}
+`
+	if err := os.WriteFile(path, []byte(corrupt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replay(path); err == nil {
+		t.Error("a corrupt line before the last one was ignored")
+	}
+}
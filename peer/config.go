@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fileConfig is the configuration file given with -config, in JSON:
+//
+//	{
+//		"algorithm": "ricart-agrawala",
+//		"timeout": "30s",
+//		"coordinator": "alice",
+//		"peers": [
+//			{"id": "alice", "address": "127.0.0.1", "port": 50051},
+//			{"id": "bob", "name": "Bob", "address": "127.0.0.1", "port": 50052, "parent": "alice"}
+//		]
+//	}
+//
+// The options not given keep the value of the command line flags.
+// The peers are in the same order for everybody, the first one starts with the token.
+type fileConfig struct {
+	Algorithm string    `json:"algorithm"`
+	Timeout   *duration `json:"timeout"`
+	Heartbeat *duration `json:"heartbeat"`
+	Election  string    `json:"election"`
+	// id of the coordinator of the centralized algorithm, the first peer when empty
+	Coordinator string `json:"coordinator"`
+	Slots       int    `json:"slots"`
+	// number of slots of some named resources, slots for the others
+	ResourceSlots map[string]int `json:"resource_slots"`
+	// certificate of the authority signing the certificates of the peers, for mutual TLS
+	CA string `json:"ca"`
+	// file with the key shared by all the peers to sign the messages
+	Secret string       `json:"secret"`
+	Peers  []peerConfig `json:"peers"`
+}
+
+// peerConfig is a peer of the configuration file
+type peerConfig struct {
+	// ID identifies the peer, it is given with -id and orders the requests made at the same Lamport time
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+	// id of the parent in the tree of raymond, the peers make a binary tree
+	// in the file order when missing and empty is the root
+	Parent   *string `json:"parent"`
+	StateLog string  `json:"statelog"`
+	// certificate and key of the peer for mutual TLS, with ca
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+}
+
+// duration is a time.Duration written as a string, "30s"
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %v", err)
+	}
+	value, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = duration(value)
+	return nil
+}
+
+// resourceSlots is the repeatable -resource-slots flag, "name=k"
+type resourceSlots map[string]int
+
+func (r resourceSlots) String() string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + strconv.Itoa(r[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (r resourceSlots) Set(value string) error {
+	name, slots, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("%q is not name=k", value)
+	}
+	k, err := strconv.Atoi(slots)
+	if err != nil || k <= 0 {
+		return fmt.Errorf("the slots of %q must be a positive number, not %q", name, slots)
+	}
+	r[name] = k
+	return nil
+}
+
+// loadConfig reads and checks the configuration file at path
+func loadConfig(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error while opening the configuration file: %v", err)
+	}
+	c := &fileConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return nil, fmt.Errorf("Error in reading the configuration file %s: %v", path, err)
+	}
+	if problems := c.check(); len(problems) > 0 {
+		return nil, fmt.Errorf("Errors in the configuration file %s:\n  %s", path, strings.Join(problems, "\n  "))
+	}
+	return c, nil
+}
+
+// check returns all the errors of the configuration, not only the first one
+func (c *fileConfig) check() []string {
+	problems := []string{}
+	if len(c.Peers) == 0 {
+		problems = append(problems, "no peers")
+	}
+	ids := make(map[string]int)
+	refs := make(map[string]string)
+	for i, p := range c.Peers {
+		where := fmt.Sprintf("peer %d", i+1)
+		if p.ID != "" {
+			where = fmt.Sprintf("peer %d (%s)", i+1, p.ID)
+		}
+		if p.ID == "" {
+			problems = append(problems, where+": missing id")
+		} else if _, found := ids[p.ID]; found {
+			problems = append(problems, fmt.Sprintf("%s: id already used by peer %d", where, ids[p.ID]+1))
+		} else {
+			ids[p.ID] = i
+		}
+		if err := checkAddress(p.Address); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", where, err))
+		}
+		if p.Port <= 0 || p.Port > 65535 {
+			problems = append(problems, fmt.Sprintf("%s: port %d out of range 1-65535", where, p.Port))
+		}
+		ref := p.Address + ":" + strconv.Itoa(p.Port)
+		if other, found := refs[ref]; found {
+			problems = append(problems, fmt.Sprintf("%s: address %s already used by %s", where, ref, other))
+		} else {
+			refs[ref] = where
+		}
+		// the files of the others peers are on their machines, only the presence is checked
+		if c.CA != "" && (p.Cert == "" || p.Key == "") {
+			problems = append(problems, where+": missing cert or key, required with ca")
+		}
+		if c.CA == "" && (p.Cert != "" || p.Key != "") {
+			problems = append(problems, where+": cert and key need ca")
+		}
+	}
+	parents := make([]int, len(c.Peers))
+	for i, p := range c.Peers {
+		switch {
+		case p.Parent == nil:
+			// binary tree in the file order
+			parents[i] = (i - 1) / 2
+			if i == 0 {
+				parents[i] = root
+			}
+		case *p.Parent == "":
+			parents[i] = root
+		default:
+			parent, found := ids[*p.Parent]
+			if !found {
+				problems = append(problems, fmt.Sprintf("peer %d (%s): unknown parent %q", i+1, p.ID, *p.Parent))
+				parent = notPeer
+			}
+			parents[i] = parent
+		}
+	}
+	peerName := func(i int) string { return fmt.Sprintf("peer %d (%s)", i+1, c.Peers[i].ID) }
+	for _, problem := range checkTree(parents, peerName) {
+		if problem.index < 0 {
+			problems = append(problems, problem.text)
+		} else {
+			problems = append(problems, peerName(problem.index)+": "+problem.text)
+		}
+	}
+	if _, found := ids[c.Coordinator]; c.Coordinator != "" && !found {
+		problems = append(problems, fmt.Sprintf("unknown coordinator %q", c.Coordinator))
+	}
+	if c.Slots < 0 {
+		problems = append(problems, fmt.Sprintf("slots %d must be positive", c.Slots))
+	}
+	names := make([]string, 0, len(c.ResourceSlots))
+	for name := range c.ResourceSlots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c.ResourceSlots[name] <= 0 {
+			problems = append(problems, fmt.Sprintf("resource_slots: %q has %d slots, it must be positive", name, c.ResourceSlots[name]))
+		}
+	}
+	if c.Timeout != nil && *c.Timeout < 0 {
+		problems = append(problems, "timeout must be positive")
+	}
+	if c.Heartbeat != nil && *c.Heartbeat < 0 {
+		problems = append(problems, "heartbeat must be positive")
+	}
+	if c.Secret != "" {
+		if _, err := os.Stat(c.Secret); err != nil {
+			problems = append(problems, fmt.Sprintf("secret: %v", err))
+		}
+	}
+	if c.CA != "" {
+		if _, err := os.Stat(c.CA); err != nil {
+			problems = append(problems, fmt.Sprintf("ca: %v", err))
+		}
+	}
+	return problems
+}
+
+// checkAddress accepts an IP address or a host name
+func checkAddress(address string) error {
+	if address == "" {
+		return fmt.Errorf("missing address")
+	}
+	if net.ParseIP(address) != nil {
+		return nil
+	}
+	for _, label := range strings.Split(address, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("bad address %q", address)
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return fmt.Errorf("bad address %q", address)
+			}
+		}
+	}
+	return nil
+}
+
+// index returns the position of the peer with the given id, -1 if there is none
+func (c *fileConfig) index(id string) int {
+	for i, p := range c.Peers {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// rows returns the peers in the format of the rows of confFile.csv
+func (c *fileConfig) rows() [][]string {
+	rows := [][]string{}
+	for _, p := range c.Peers {
+		row := []string{p.Address, strconv.Itoa(p.Port)}
+		if p.Parent != nil {
+			parentRow := ""
+			if *p.Parent != "" {
+				parentRow = strconv.Itoa(c.index(*p.Parent))
+			}
+			row = append(row, parentRow)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// parent of the root and of the rows that are not peers in checkTree
+const (
+	root    = -1
+	notPeer = -2
+)
+
+// treeProblem is what is wrong with the peer at index in the tree of the
+// parents, index is -1 for the tree as a whole
+type treeProblem struct {
+	index int
+	text  string
+}
+
+// checkTree checks that parents, the index of the parent of every peer, make
+// a single tree: exactly one root and no cycle. The peers are named by name
+// in the problems.
+func checkTree(parents []int, name func(int) string) []treeProblem {
+	problems := []treeProblem{}
+	first := -1
+	for i, parent := range parents {
+		if parent != root {
+			continue
+		}
+		if first >= 0 {
+			problems = append(problems, treeProblem{i, fmt.Sprintf("second root, %s is already the root", name(first))})
+		} else {
+			first = i
+		}
+	}
+	if first < 0 {
+		return append(problems, treeProblem{-1, "no root, every peer has a parent"})
+	}
+	for i, parent := range parents {
+		if parent == notPeer {
+			continue
+		}
+		// going up from a peer reaches a root in less steps than the peers, or it's a cycle
+		at := i
+		for steps := 0; at >= 0 && steps <= len(parents); steps++ {
+			at = parents[at]
+		}
+		if at >= 0 {
+			// report the cycle once
+			return append(problems, treeProblem{i, "the parents make a cycle"})
+		}
+	}
+	return problems
+}
+
+// checkRows reports the rows of confFile.csv with a port or a parent that is
+// not a number or an address already used, a parent row that is not a peer
+// and parents that don't make a single tree. The rows with less than 2 columns are ignored.
+func checkRows(rows [][]string) error {
+	refs := make(map[string]int)
+	parents := make([]int, len(rows))
+	explicit := false
+	for i, row := range rows {
+		parents[i] = notPeer
+		if len(row) < 2 {
+			continue
+		}
+		// binary tree in the file order without the third column
+		parents[i] = (i - 1) / 2
+		if i == 0 {
+			parents[i] = root
+		}
+		if port, err := strconv.Atoi(row[1]); err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("Error in %s line %d: bad port %q", *csvPath, i+1, row[1])
+		}
+		if err := checkAddress(row[0]); err != nil {
+			return fmt.Errorf("Error in %s line %d: %v", *csvPath, i+1, err)
+		}
+		if len(row) > 2 {
+			explicit = true
+			parents[i] = root
+			if row[2] != "" {
+				parent, err := strconv.Atoi(row[2])
+				if err != nil {
+					return fmt.Errorf("Error in %s line %d: bad parent row %q", *csvPath, i+1, row[2])
+				}
+				if parent < 0 || parent >= len(rows) || len(rows[parent]) < 2 {
+					return fmt.Errorf("Error in %s line %d: parent row %d is not a peer", *csvPath, i+1, parent)
+				}
+				parents[i] = parent
+			}
+		}
+		ref := row[0] + ":" + row[1]
+		if other, found := refs[ref]; found {
+			return fmt.Errorf("Error in %s line %d: address %s already used at line %d", *csvPath, i+1, ref, other+1)
+		}
+		refs[ref] = i
+	}
+	if !explicit {
+		// the binary tree is right by construction
+		return nil
+	}
+	lineName := func(i int) string { return fmt.Sprintf("line %d", i+1) }
+	if problems := checkTree(parents, lineName); len(problems) > 0 {
+		if problems[0].index < 0 {
+			return fmt.Errorf("Error in %s: %s", *csvPath, problems[0].text)
+		}
+		return fmt.Errorf("Error in %s %s: %s", *csvPath, lineName(problems[0].index), problems[0].text)
+	}
+	return nil
+}
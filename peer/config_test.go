@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRowsParents(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  [][]string
+		error string
+	}{
+		{"binary tree", [][]string{{"127.0.0.1", "1"}, {"127.0.0.1", "2"}, {"127.0.0.1", "3"}}, ""},
+		{"explicit tree", [][]string{{"127.0.0.1", "1", "1"}, {"127.0.0.1", "2", ""}, {"127.0.0.1", "3", "0"}}, ""},
+		{"out of range", [][]string{{"127.0.0.1", "1", ""}, {"127.0.0.1", "2", "5"}}, "line 2: parent row 5 is not a peer"},
+		{"two roots", [][]string{{"127.0.0.1", "1", ""}, {"127.0.0.1", "2", ""}}, "line 2: second root, line 1 is already the root"},
+		{"cycle", [][]string{{"127.0.0.1", "1", ""}, {"127.0.0.1", "2", "2"}, {"127.0.0.1", "3", "1"}}, "line 2: the parents make a cycle"},
+		{"no root", [][]string{{"127.0.0.1", "1", "1"}, {"127.0.0.1", "2", "0"}}, "no root"},
+	}
+	for _, test := range tests {
+		err := checkRows(test.rows)
+		switch {
+		case test.error == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		case test.error != "" && (err == nil || !strings.Contains(err.Error(), test.error)):
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.error)
+		}
+	}
+}
+
+func TestCheckParents(t *testing.T) {
+	parent := func(id string) *string { return &id }
+	c := &fileConfig{Peers: []peerConfig{
+		{ID: "a", Address: "127.0.0.1", Port: 1, Parent: parent("")},
+		{ID: "b", Address: "127.0.0.1", Port: 2, Parent: parent("")},
+		{ID: "c", Address: "127.0.0.1", Port: 3, Parent: parent("d")},
+		{ID: "d", Address: "127.0.0.1", Port: 4, Parent: parent("c")},
+	}}
+	problems := strings.Join(c.check(), "\n")
+	for _, want := range []string{
+		"peer 2 (b): second root, peer 1 (a) is already the root",
+		"peer 3 (c): the parents make a cycle",
+	} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("problems %q don't contain %q", problems, want)
+		}
+	}
+}
+
+func TestResourceSlotsFlag(t *testing.T) {
+	slots := resourceSlots{}
+	for _, value := range []string{"db=2", "cache=3"} {
+		if err := slots.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := slots.String(); got != "cache=3,db=2" {
+		t.Errorf("String() = %q", got)
+	}
+	for _, value := range []string{"db", "db=0", "db=-1", "db=x"} {
+		if err := slots.Set(value); err == nil {
+			t.Errorf("Set(%q) accepted", value)
+		}
+	}
+}
@@ -1,85 +1,89 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
 	"log"
-	"math"
 	"math/rand"
-	"net"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
-	"sync"
+	"strings"
+	"syscall"
 	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-
-	proto "MutualExclusion/grpc"
+	"MutualExclusion/mutex"
 )
 
-// link for Ricart & Agrawala algorithm https://www.geeksforgeeks.org/ricart-agrawala-algorithm-in-mutual-exclusion-in-distributed-system/
-// we need only the port where we receive messages
-// output port is decided automatically randomly by operating system
-
-type Peer struct {
-	proto.UnimplementedMutualExlusionServiceServer
-	name    string
-	address string
-	port    int
-}
-
-// peer states
-const (
-	Released int = 0
-	Wanted       = 1
-	Held         = 2
-)
+// the distributed mutex is implemented in the mutex package, this is only
+// the command line interface to use it
 
 var (
-	my_row = flag.Int("row", 1, "Indicate the row of parameter file for this peer") // set with "-row <port>" in terminal
-	name   = flag.String("name", "peer", "name of the peer")
-	// Lamport variable
-	lamport_time = 0
-	confFile     = "confFile.csv"
+	my_row    = flag.Int("row", 1, "Indicate the row of parameter file for this peer") // set with "-row <port>" in terminal
+	name      = flag.String("name", "peer", "name of the peer")
+	algorithm = flag.String("algorithm", mutex.RicartAgrawala, "mutual exclusion algorithm used by all the peers: "+
+		mutex.RicartAgrawala+", "+mutex.Lamport+", "+mutex.SuzukiKasami+", "+mutex.Maekawa+", "+mutex.Raymond+" or "+mutex.Central)
+	coordinator = flag.Int("coordinator", 0, "row of the coordinator for the "+mutex.Central+" algorithm")
+	election    = flag.String("election", mutex.Bully, "algorithm electing a new coordinator when it fails: "+mutex.Bully+" or "+mutex.Ring)
+	slots       = flag.Int("slots", 1, "number of peers that can be in the critical section at the same time, only with "+mutex.RicartAgrawala)
+	heartbeat   = flag.Duration("heartbeat", 0, "interval of the heartbeats of the failure detector, disabled when 0")
+	stateLog    = flag.String("statelog", "", "file where the clock and the state are logged to recover after a crash, only with "+mutex.RicartAgrawala)
+	join        = flag.Bool("join", false, "join the running peers through the first row answering, instead of connecting to all the rows")
+	timeout     = flag.Duration("timeout", 0, "maximum time to wait for the permission of the others peers, 0 waits forever")
+	port        = flag.Int("port", 0, "port of this peer, to discover the others by gossip instead of reading the configuration file")
+	address     = flag.String("address", "127.0.0.1", "address of this peer, with -port")
+	seeds       = flag.String("seeds", "", "comma separated \"address:port\" of running peers to discover the others from, with -port")
+	gossip      = flag.Duration("gossip", time.Second, "interval of the gossip discovering the peers, with -port")
+	csvPath     = flag.String("csv", "confFile.csv", "file with a row \"address,port\" for every peer")
+	configPath  = flag.String("config", "", "JSON configuration file of the peers and their options, used instead of the -csv file")
+	id          = flag.String("id", "", "id of this peer in the -config file, used instead of -row")
+	certFile    = flag.String("cert", "", "certificate of this peer for mutual TLS, its common name is \"address:port\"")
+	keyFile     = flag.String("key", "", "key of the -cert certificate")
+	caFile      = flag.String("ca", "", "certificate of the authority signing the certificates of the peers, with -cert")
+	secretFile  = flag.String("secret", "", "file with the key shared by all the peers to sign the messages")
+	// slots of the named resources, -resource-slots
+	slotsOf = resourceSlots{}
+	// id of this peer in the -config file, empty otherwise
+	peerID = ""
 	// default values for address and port
 	my_address = "127.0.0.1"
 	my_port    = 50050
-	// store tcp connection to others peers
-	peers = make(map[string]proto.MutualExlusionServiceClient)
-	// state of the distributed mutex
-	state = Released
-	// lamport time of this peers request
-	myRequestTime = 0
-	// wait for listen before try to connect to other peers
-	wg sync.WaitGroup
 )
 
 func main() {
+	flag.Var(slotsOf, "resource-slots", "\"name=k\" number of peers that can use the resource name at the same time, can be repeated, only with "+mutex.RicartAgrawala)
 	flag.Parse()
 
-	// read from confFile.txt and set the peer values
-	csvFile, err := os.Open(confFile)
-	if err != nil {
-		fmt.Printf("Error while opening CSV file: %v\n", err)
+	if *port > 0 {
+		discover()
 		return
 	}
-	defer csvFile.Close()
 
-	reader := csv.NewReader(csvFile)
-	rows, err := reader.ReadAll()
+	if *configPath != "" {
+		if err := applyConfig(); err != nil {
+			fmt.Printf("%v\n", err)
+			return
+		}
+	}
+
+	// read from confFile.txt and set the peer values
+	rows, err := readConf()
 	if err != nil {
-		fmt.Printf("Error in reading CSV file: %v\n", err)
+		fmt.Printf("%v\n", err)
 		return
 	}
 
 	found := false
 	for index, row := range rows {
-		if index == *my_row {
+		if index == *my_row && len(row) >= 2 {
 			fmt.Printf("Your settings are : %s address, %s port\n", row[0], row[1])
 			my_address = row[0]
+			// checked by readConf
 			my_port, _ = strconv.Atoi(row[1])
 			found = true
 			break
@@ -90,191 +94,459 @@ func main() {
 		fmt.Printf("Row with parameters not founded\n")
 		return
 	}
-
-	peer := &Peer{
-		name:    *name,
-		address: my_address,
-		port:    my_port,
+	if *coordinator < 0 || *coordinator >= len(rows) || len(rows[*coordinator]) < 2 {
+		fmt.Printf("Row of the coordinator not founded\n")
+		return
 	}
-	// wait for opening port to listen
-	wg.Add(1)
 
+	key, err := secret()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	parentRef, err := parent(rows)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	m, err := mutex.NewLocker(*algorithm, mutex.Config{
+		ID:            peerID,
+		Name:          *name,
+		Address:       my_address,
+		Port:          my_port,
+		Timeout:       *timeout,
+		Heartbeat:     *heartbeat,
+		StateLog:      *stateLog,
+		Slots:         *slots,
+		ResourceSlots: slotsOf,
+		Members:       members(rows),
+		Parent:        parentRef,
+		// rows[*coordinator] exists, checked with my row
+		Coordinator: rows[*coordinator][0] + ":" + rows[*coordinator][1],
+		Election:    *election,
+		TLS:         tlsFiles(),
+		Secret:      key,
+		// with a token based algorithm the first peer of the configuration file starts with the token
+		Token: *my_row == 0,
+	})
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
 	// open the port to new connections
-	go StartListen(peer)
+	if err := m.Listen(); err != nil {
+		log.Fatalf("Could not create the peer %v", err)
+	}
+	defer m.Close()
 
-	wg.Wait()
 	// Preparate tcp connection to the others client
-	connectToOthersPeer(peer)
+	if *join {
+		joinOthersPeer(m, rows)
+	} else {
+		connectToOthersPeer(m, rows)
+	}
+	go watchConf(m)
 
 	// user interface menu
-	doSomething()
+	doSomething(m)
 }
 
-func StartListen(peer *Peer) {
-	// Create a new grpc server
-	grpcPeer := grpc.NewServer()
-
-	increaseTime()
-	// Make the peer listen at the given port (convert int port to string)
-	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s", peer.address, strconv.Itoa(peer.port)))
-
+// discover starts a peer that finds the others by gossip from the seeds,
+// without the configuration file
+func discover() {
+	seedRefs := []string{}
+	for _, seed := range strings.Split(*seeds, ",") {
+		if seed = strings.TrimSpace(seed); seed != "" {
+			seedRefs = append(seedRefs, seed)
+		}
+	}
+	self := *address + ":" + strconv.Itoa(*port)
+	// the first peer, without seeds, is the coordinator and has the token,
+	// the others take the coordinator of the members when they join
+	coordinatorRef := self
+	if len(seedRefs) > 0 {
+		coordinatorRef = seedRefs[0]
+	}
+	key, err := secret()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	m, err := mutex.NewLocker(*algorithm, mutex.Config{
+		Name:          *name,
+		Address:       *address,
+		Port:          *port,
+		Timeout:       *timeout,
+		Heartbeat:     *heartbeat,
+		StateLog:      *stateLog,
+		Slots:         *slots,
+		ResourceSlots: slotsOf,
+		Members:       []string{self},
+		Coordinator:   coordinatorRef,
+		Election:      *election,
+		TLS:           tlsFiles(),
+		Secret:        key,
+		Gossip:        *gossip,
+		Seeds:         seedRefs,
+		Token:         len(seedRefs) == 0,
+	})
 	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	if err := m.Listen(); err != nil {
 		log.Fatalf("Could not create the peer %v", err)
 	}
-	log.Printf("Lamport %d: Started peer receiving at address: %s and at port: %d\n", lamport_time, peer.address, peer.port)
-	wg.Done()
+	defer m.Close()
+	doSomething(m)
+}
+
+// applyConfig selects this peer in the -config file and sets the options it gives
+func applyConfig() error {
+	c, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *id == "" {
+		return fmt.Errorf("-id of this peer is required with -config")
+	}
+	*my_row = c.index(*id)
+	if *my_row < 0 {
+		return fmt.Errorf("Peer %q not found in the configuration file %s", *id, *configPath)
+	}
+	// the flags given on the command line win over the file
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	self := c.Peers[*my_row]
+	peerID = self.ID
+	if c.Secret != "" && !given["secret"] {
+		*secretFile = c.Secret
+	}
+	if c.CA != "" && !given["ca"] {
+		*caFile = c.CA
+	}
+	if self.Cert != "" && !given["cert"] {
+		*certFile = self.Cert
+	}
+	if self.Key != "" && !given["key"] {
+		*keyFile = self.Key
+	}
+	if self.Name != "" && !given["name"] {
+		*name = self.Name
+	}
+	if self.StateLog != "" && !given["statelog"] {
+		*stateLog = self.StateLog
+	}
+	if c.Algorithm != "" && !given["algorithm"] {
+		*algorithm = c.Algorithm
+	}
+	if c.Election != "" && !given["election"] {
+		*election = c.Election
+	}
+	if c.Coordinator != "" && !given["coordinator"] {
+		*coordinator = c.index(c.Coordinator)
+	}
+	if c.Slots > 0 && !given["slots"] {
+		*slots = c.Slots
+	}
+	if len(c.ResourceSlots) > 0 && !given["resource-slots"] {
+		slotsOf = c.ResourceSlots
+	}
+	if c.Timeout != nil && !given["timeout"] {
+		*timeout = time.Duration(*c.Timeout)
+	}
+	if c.Heartbeat != nil && !given["heartbeat"] {
+		*heartbeat = time.Duration(*c.Heartbeat)
+	}
+	return nil
+}
 
-	// Register the grpc service
-	increaseTime()
-	proto.RegisterMutualExlusionServiceServer(grpcPeer, peer)
-	serveError := grpcPeer.Serve(listener)
+// tlsFiles returns the files of the mutual TLS, nil without -cert
+func tlsFiles() *mutex.TLS {
+	if *certFile == "" {
+		return nil
+	}
+	return &mutex.TLS{CertFile: *certFile, KeyFile: *keyFile, CAFile: *caFile}
+}
 
-	if serveError != nil {
-		log.Fatalf("Could not serve listener")
+// secret returns the key in the -secret file, nil without it
+func secret() ([]byte, error) {
+	if *secretFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(*secretFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error while opening the secret file: %v", err)
 	}
-	log.Printf("Lamport %d: Started gRPC service", lamport_time)
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("The secret file %s is empty", *secretFile)
+	}
+	return key, nil
+}
 
+// confFile is the path of the file of the peers, the -config one if given
+func confFile() string {
+	if *configPath != "" {
+		return *configPath
+	}
+	return *csvPath
 }
 
-// Connect to others peer
-func connectToOthersPeer(p *Peer) {
-	// read csv file
-	file, err := os.Open(confFile)
+// readConf reads the rows of the configuration file, the -config one in the same format
+func readConf() ([][]string, error) {
+	if *configPath != "" {
+		c, err := loadConfig(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		return c.rows(), nil
+	}
+	csvFile, err := os.Open(*csvPath)
 	if err != nil {
-		log.Fatalf("Failed to open configuration file: %v", err)
+		return nil, fmt.Errorf("Error while opening CSV file: %v", err)
 	}
-	defer file.Close()
+	defer csvFile.Close()
 
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(csvFile)
+	// the third column, the parent, is optional
+	reader.FieldsPerRecord = -1
 	rows, err := reader.ReadAll()
 	if err != nil {
-		log.Fatalf("Failed to read file data: %v", err)
+		return nil, fmt.Errorf("Error in reading CSV file: %v", err)
+	}
+	return rows, checkRows(rows)
+}
+
+// watchConf reloads the configuration file when it changes, checked every
+// second, or on SIGHUP, and connects and disconnects the peers to match the rows.
+// The rows are "address:port", so a row moved to another line is the same peer.
+func watchConf(m mutex.Locker) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	modified := time.Time{}
+	if info, err := os.Stat(confFile()); err == nil {
+		modified = info.ModTime()
+	}
+	for {
+		select {
+		case <-hangup:
+			log.Printf("SIGHUP received, reloading %s", confFile())
+		case <-ticker.C:
+			info, err := os.Stat(confFile())
+			if err != nil || info.ModTime().Equal(modified) {
+				continue
+			}
+			modified = info.ModTime()
+			log.Printf("%s changed, reloading it", confFile())
+		}
+		reloadConf(m)
+	}
+}
+
+// reloadConf applies the rows of the configuration file, a file not valid is ignored
+func reloadConf(m mutex.Locker) {
+	rows, err := readConf()
+	if err != nil {
+		log.Printf("Configuration not reloaded: %v", err)
+		return
+	}
+	self := my_address + ":" + strconv.Itoa(my_port)
+	if !contains(members(rows), self) {
+		log.Printf("Configuration not reloaded: this peer %s is not in %s anymore, use 'leave' to quit", self, confFile())
+		return
+	}
+	added, removed, err := m.SetMembers(members(rows))
+	if err != nil {
+		log.Printf("Configuration not reloaded: %v", err)
+		return
+	}
+	log.Printf("Configuration reloaded: %d peers added %v, %d removed %v", len(added), added, len(removed), removed)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
+}
 
+// Connect to others peer
+func connectToOthersPeer(m mutex.Locker, rows [][]string) {
 	// try to connect to other peers
 	for index, row := range rows {
 		if len(row) < 2 || (index == *my_row) {
 			// ignore corrupted rows and me
 			continue
 		}
-		peerAddress := row[0]
 		peerPort, _ := strconv.Atoi(row[1])
-		peerRef := row[0] + ":" + row[1]
-		// retrieve connection
-		connection := connectToPeer(peerAddress, peerPort)
-		// add to map
-		peers[peerRef] = connection
+		m.Connect(row[0], peerPort)
 	}
 }
 
-func connectToPeer(address string, port int) proto.MutualExlusionServiceClient {
-	// Dial doesn't check if the peer at that address:host is effectivly on (simply prepare TCP connection)
-	increaseTime()
-	conn, err := grpc.Dial(address+":"+strconv.Itoa(port), grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Printf("Lamport %d: Could not connect to peer %s at port %d", lamport_time, address, port)
-	} else {
-		log.Printf("Lamport %d: Created TCP connection to the %s address at port %d\n", lamport_time, address, port)
+// joinOthersPeer asks the rows, in the file order, to add this peer to the running ones
+func joinOthersPeer(m mutex.Locker, rows [][]string) {
+	for index, row := range rows {
+		if len(row) < 2 || (index == *my_row) {
+			continue
+		}
+		peerPort, _ := strconv.Atoi(row[1])
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := m.Join(ctx, row[0], peerPort)
+		cancel()
+		if err == nil {
+			return
+		}
+		log.Printf("Could not join through %s:%s: %v", row[0], row[1], err)
 	}
-	return proto.NewMutualExlusionServiceClient(conn)
+	log.Printf("Nobody to join, starting alone")
 }
 
-func (peer *Peer) AskPermission(ctx context.Context, in *proto.Question) (*proto.Answer, error) {
-	setTime(int(in.Time))
-	// check if the peer requesting permission is not in the list of connected peers
-	// it can be a reconnected peer or one not present in the configuration file
-	peerRef := in.ClientReference.ClientAddress + ":" + strconv.Itoa(int(in.ClientReference.ClientPort))
-	log.Printf("Lamport %d: Peer [%s] asked for a mutual exection", lamport_time, peerRef)
-	found := false
-	for index := range peers {
-		if index == peerRef {
-			found = true
-			break
+// members returns the "address:port" of all the valid rows, in the file order
+func members(rows [][]string) []string {
+	members := []string{}
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
 		}
+		members = append(members, row[0]+":"+row[1])
 	}
-	// receive request from a not known peer
-	if !found {
-		connection := connectToPeer(in.ClientReference.ClientAddress, int(in.ClientReference.ClientPort))
-		peers[peerRef] = connection
-	}
-	// Ricart–Agrawala Algorithm
-	if (state == Held) || (state == Wanted && (in.Time > int32(myRequestTime))) {
-		// queue the reply (just wait unline i'm done)
-		for state == Held || state == Wanted {
-			time.Sleep(500 * time.Millisecond)
+	return members
+}
+
+// parent returns the "address:port" of the parent of this peer in the spanning
+// tree of the tree based algorithms. It is the row given in the optional third
+// column or, without it, the rows make a binary tree in the file order.
+// The root has no parent. A parent row that is not a peer is an error, it
+// would make this peer a second root with a second token.
+func parent(rows [][]string) (string, error) {
+	if row := rows[*my_row]; len(row) > 2 {
+		if row[2] == "" {
+			return "", nil
+		}
+		parentRow, err := strconv.Atoi(row[2])
+		if err != nil || parentRow < 0 || parentRow >= len(rows) || len(rows[parentRow]) < 2 || parentRow == *my_row {
+			return "", fmt.Errorf("Error in %s line %d: bad parent row %q", confFile(), *my_row+1, row[2])
 		}
+		return rows[parentRow][0] + ":" + rows[parentRow][1], nil
+	}
+	if *my_row == 0 {
+		return "", nil
+	}
+	parentRow := (*my_row - 1) / 2
+	if len(rows[parentRow]) < 2 {
+		return "", fmt.Errorf("Error in %s line %d: the parent line %d has no address and port", confFile(), *my_row+1, parentRow+1)
 	}
-	log.Printf("Lamport %d: Peer [%s] authorized to do mutual exection", lamport_time, peerRef)
-	increaseTime()
-	return &proto.Answer{
-		Reply: true,
-		Time:  int32(lamport_time),
-	}, nil
+	return rows[parentRow][0] + ":" + rows[parentRow][1], nil
+}
 
+// lock is what the commands lock: the peer itself or one of its named resources
+type lock interface {
+	Lock(ctx context.Context) error
+	TryLock(ctx context.Context) (bool, error)
+	Unlock()
 }
 
-func doSomething() {
+func doSomething(m mutex.Locker) {
+	input := bufio.NewScanner(os.Stdin)
 	for {
-		var text string
-		log.Printf("Insert 'mutual' to do mutual execution or 'exit' to quit or anything else "+
-			"to increment time [Actual Lamport Time: %d] ", lamport_time)
-		fmt.Scanln(&text)
+		log.Printf("Insert 'mutual' to do mutual execution, 'try' to do it only if nobody else is, "+
+			"'read' to do it together with the others readers, followed by the name of a resource to lock only it, "+
+			"'peers' to show the peers alive and suspected, 'leave' to quit telling the others, 'exit' to quit or anything else to increment time [Actual Lamport Time: %d] ", m.Clock().Now())
+		input.Scan()
+		fields := strings.Fields(input.Text())
+		text := ""
+		if len(fields) > 0 {
+			text = fields[0]
+		}
 
-		increaseTime() // an event occurred
 		if text == "exit" {
 			break
 		}
-
-		if text != "mutual" {
-			continue
+		if text == "leave" {
+			if err := m.Leave(context.Background()); err != nil {
+				log.Printf("Could not leave: %v", err)
+				continue
+			}
+			break
 		}
 
-		state = Wanted
-		myRequestTime = lamport_time
-
-		// Peers enters the critical section if it has received the REPLY message from all other sites.
-		peerRef := &proto.ClientReference{
-			ClientAddress: my_address,
-			ClientPort:    int32(my_port),
-			ClientName:    *name,
+		var l lock = m
+		if len(fields) > 1 && (text == "mutual" || text == "try" || text == "read") {
+			named, ok := m.(interface{ Resource(string) *mutex.Resource })
+			if !ok {
+				log.Printf("The %s algorithm has no named resources", *algorithm)
+				continue
+			}
+			l = named.Resource(fields[1])
 		}
-		for index, peer := range peers {
-			increaseTime()
-			log.Printf("Lamport %d: Asked Peer [%s] for permission", lamport_time, index)
-			answer, err := peer.AskPermission(context.Background(),
-				&proto.Question{
-					ClientReference: peerRef,
-					Time:            int32(myRequestTime),
-				})
+
+		switch text {
+		case "peers":
+			showPeers(m)
+			continue
+		case "mutual":
+			if err := l.Lock(context.Background()); err != nil {
+				log.Printf("Could not enter the critical section: %v", err)
+				continue
+			}
+		case "try":
+			ok, err := l.TryLock(context.Background())
 			if err != nil {
-				log.Printf("Lamport %d: Peer [%s] no more available, removed from connected peers", lamport_time, index)
-				delete(peers, index)
+				log.Printf("Could not enter the critical section: %v", err)
+				continue
+			}
+			if !ok {
+				log.Printf("Critical section busy, try again later")
+				continue
+			}
+		case "read":
+			rw, ok := l.(interface {
+				RLock(ctx context.Context) error
+				RUnlock()
+			})
+			if !ok {
+				log.Printf("The %s algorithm has no shared mode", *algorithm)
 				continue
-			} else {
-				setTime(int(answer.Time))
-				log.Printf("Lamport %d: Got permission from peer [%s]", lamport_time, index)
 			}
+			if err := rw.RLock(context.Background()); err != nil {
+				log.Printf("Could not enter the critical section: %v", err)
+				continue
+			}
+			criticalSection()
+			rw.RUnlock()
+			continue
+		default:
+			m.Clock().Tick() // an event occurred
+			continue
 		}
 		// do critical section
 		criticalSection()
+		l.Unlock()
 	}
 }
 
-func criticalSection() {
-	increaseTime()
-	state = Held
-	log.Printf("Lamport %d: Starting critical section", lamport_time)
-	time.Sleep(time.Duration(rand.Intn(4)+10) * time.Second)
-	increaseTime()
-	log.Printf("Lamport %d: Ending critical section", lamport_time)
-	state = Released
-}
-
-func increaseTime() {
-	lamport_time++
+// showPeers prints the view of the failure detector
+func showPeers(m mutex.Locker) {
+	view := m.Peers()
+	peerRefs := make([]string, 0, len(view))
+	for peerRef := range view {
+		peerRefs = append(peerRefs, peerRef)
+	}
+	sort.Strings(peerRefs)
+	for _, peerRef := range peerRefs {
+		status := "alive"
+		if !view[peerRef] {
+			status = "suspected"
+		}
+		log.Printf("Peer [%s] %s", peerRef, status)
+	}
 }
 
-func setTime(received int) {
-	max := math.Max(float64(received), float64(lamport_time))
-	lamport_time = int(max + 1)
+func criticalSection() {
+	time.Sleep(time.Duration(rand.Intn(4)+10) * time.Second)
 }